@@ -0,0 +1,249 @@
+package adblock
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*ads*", "https://example.com/ads/banner.js", true},
+		{"*ads*", "https://example.com/news", false},
+		{"/banner/*/img*", "/banner/foo/img.png", true},
+		{"/banner/*/img*", "/banner/img.png", false},
+		{"^ad.js", "/ad.js", true},
+		{"^ad.js", "ad.js", false},
+		{"/ad^*", "/ad?x=1", true},
+		{"/ad^", "/ad", true},    // "^" matches the end
+		{"/ad^", "/ad-x", false}, // "-" is not a separator
+		{"/ad^*", "/ad.js", false},
+		{"exact", "exact", true},
+		{"exact", "exactly", false},
+	}
+	for _, tt := range tests {
+		if got := wildcardMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("wildcardMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestEngine_Match(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []string
+		req   Request
+		want  bool
+	}{
+		{
+			name:  "domain anchor",
+			rules: []string{"||ads.example.com^"},
+			req:   Request{URL: "https://ads.example.com/x.js", Type: TypeScript},
+			want:  true,
+		},
+		{
+			name:  "domain anchor matches subdomains",
+			rules: []string{"||example.com^"},
+			req:   Request{URL: "https://cdn.ads.example.com/x.js", Type: TypeScript},
+			want:  true,
+		},
+		{
+			name:  "domain anchor needs a label boundary",
+			rules: []string{"||example.com^"},
+			req:   Request{URL: "https://notexample.com/x.js", Type: TypeScript},
+			want:  false,
+		},
+		{
+			name:  "domain anchor with path",
+			rules: []string{"||example.com/ads/"},
+			req:   Request{URL: "https://www.example.com/ads/1.png", Type: TypeImage},
+			want:  true,
+		},
+		{
+			name:  "start anchor",
+			rules: []string{"|https://track."},
+			req:   Request{URL: "https://track.example.com/p", Type: TypePing},
+			want:  true,
+		},
+		{
+			name:  "start anchor rejects other positions",
+			rules: []string{"|https://track."},
+			req:   Request{URL: "https://example.com/?u=https://track.x", Type: TypePing},
+			want:  false,
+		},
+		{
+			name:  "end anchor",
+			rules: []string{".gif|"},
+			req:   Request{URL: "https://example.com/pixel.gif?x", Type: TypeImage},
+			want:  false,
+		},
+		{
+			name:  "separator",
+			rules: []string{"/pixel^"},
+			req:   Request{URL: "https://example.com/pixel?id=1", Type: TypeImage},
+			want:  true,
+		},
+		{
+			name:  "regex rule",
+			rules: []string{`/\/ad[0-9]+\.js/`},
+			req:   Request{URL: "https://example.com/AD12.js", Type: TypeScript},
+			want:  true,
+		},
+		{
+			name:  "match-case",
+			rules: []string{"/Banner/$match-case"},
+			req:   Request{URL: "https://example.com/banner/1", Type: TypeImage},
+			want:  false,
+		},
+		{
+			name:  "exception wins",
+			rules: []string{"||example.com^", "@@||example.com/allowed/"},
+			req:   Request{URL: "https://example.com/allowed/x.js", Type: TypeScript},
+			want:  false,
+		},
+		{
+			name:  "exception only where it matches",
+			rules: []string{"||example.com^", "@@||example.com/allowed/"},
+			req:   Request{URL: "https://example.com/other/x.js", Type: TypeScript},
+			want:  true,
+		},
+		{
+			name:  "type option",
+			rules: []string{"||example.com^$script"},
+			req:   Request{URL: "https://example.com/a.png", Type: TypeImage},
+			want:  false,
+		},
+		{
+			name:  "negated type option",
+			rules: []string{"||example.com^$~image"},
+			req:   Request{URL: "https://example.com/a.js", Type: TypeScript},
+			want:  true,
+		},
+		{
+			name:  "subdocument option",
+			rules: []string{"||adframe.net^$subdocument"},
+			req:   Request{URL: "https://adframe.net/slot", Type: TypeSubdocument},
+			want:  true,
+		},
+		{
+			name:  "frame alias",
+			rules: []string{"||adframe.net^$frame"},
+			req:   Request{URL: "https://adframe.net/slot", Type: TypeScript},
+			want:  false,
+		},
+		{
+			name:  "plain host rule blocks iframes",
+			rules: []string{"||adframe.net^"},
+			req:   Request{URL: "https://adframe.net/slot", Type: TypeSubdocument, SourceHost: "news.com"},
+			want:  true,
+		},
+		{
+			name:  "third-party on first-party request",
+			rules: []string{"||example.com^$third-party"},
+			req:   Request{URL: "https://static.example.com/a.js", Type: TypeScript, SourceHost: "www.example.com"},
+			want:  false,
+		},
+		{
+			name:  "third-party on third-party request",
+			rules: []string{"||example.com^$third-party"},
+			req:   Request{URL: "https://example.com/a.js", Type: TypeScript, SourceHost: "news.com"},
+			want:  true,
+		},
+		{
+			name:  "domain= include",
+			rules: []string{"/ads.js$domain=news.com|blog.org"},
+			req:   Request{URL: "https://cdn.net/ads.js", Type: TypeScript, SourceHost: "www.news.com"},
+			want:  true,
+		},
+		{
+			name:  "domain= other source",
+			rules: []string{"/ads.js$domain=news.com|blog.org"},
+			req:   Request{URL: "https://cdn.net/ads.js", Type: TypeScript, SourceHost: "shop.com"},
+			want:  false,
+		},
+		{
+			name:  "domain= exclude",
+			rules: []string{"/ads.js$domain=~news.com"},
+			req:   Request{URL: "https://cdn.net/ads.js", Type: TypeScript, SourceHost: "news.com"},
+			want:  false,
+		},
+		{
+			name:  "unsupported option skips the rule",
+			rules: []string{"||example.com^$redirect=noop.js"},
+			req:   Request{URL: "https://example.com/a.js", Type: TypeScript},
+			want:  false,
+		},
+		{
+			name:  "comments are ignored",
+			rules: []string{"! ||example.com^", "[Adblock Plus 2.0]"},
+			req:   Request{URL: "https://example.com/a.js", Type: TypeScript},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			for _, r := range tt.rules {
+				e.AddRule(r)
+			}
+			if got := e.Match(tt.req); got != tt.want {
+				t.Errorf("Match(%+v) = %v, want %v", tt.req, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_HiddenElements(t *testing.T) {
+	const doc = `<html><body>
+		<div id="ad-top">a</div>
+		<div class="banner sponsor">b</div>
+		<aside class="promo">c</aside>
+		<p>text</p>
+	</body></html>`
+
+	tests := []struct {
+		name  string
+		rules []string
+		host  string
+		want  string
+	}{
+		{"generic id", []string{"###ad-top"}, "example.com", "ad-top"},
+		{"generic class", []string{"##.sponsor"}, "example.com", "banner sponsor"},
+		{"domain rule on its domain", []string{"news.com##.promo"}, "www.news.com", "promo"},
+		{"domain rule elsewhere", []string{"news.com##.promo"}, "example.com", ""},
+		{"excluded domain", []string{"~news.com##.promo"}, "news.com", ""},
+		{"global exception", []string{"##.promo", "#@#.promo"}, "example.com", ""},
+		{"domain exception", []string{"##.promo", "news.com#@#.promo"}, "news.com", ""},
+		{"domain exception elsewhere", []string{"##.promo", "news.com#@#.promo"}, "example.com", "promo"},
+		{"procedural rule skipped", []string{"##.promo:has-text(x)", "example.com#?#.promo"}, "example.com", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := html.Parse(strings.NewReader(doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := New()
+			for _, r := range tt.rules {
+				e.AddRule(r)
+			}
+			var got []string
+			for _, n := range e.HiddenElements(root, tt.host) {
+				for _, a := range n.Attr {
+					if a.Key == "id" || a.Key == "class" {
+						got = append(got, a.Val)
+					}
+				}
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("hidden = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package adblock
+
+import (
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+// cosmeticMarkers separate the domain list from the body of a cosmetic
+// rule. Only "##" and "#@#" are supported; the others (uBlock procedural
+// and scriptlet rules, AdGuard CSS injection) are recognized so they are
+// not mistaken for URL filters.
+var cosmeticMarkers = []string{"##", "#@#", "#?#", "#@?#", "#$#", "#@$#", "#%#", "#@%#"}
+
+// cosmeticSeparator returns the offset of the cosmetic marker in line, or
+// -1 for network rules.
+func cosmeticSeparator(line string) int {
+	best := -1
+	for _, m := range cosmeticMarkers {
+		if i := strings.Index(line, m); i >= 0 && (best < 0 || i < best) {
+			best = i
+		}
+	}
+	return best
+}
+
+// cosmeticRule is a compiled element-hiding rule.
+type cosmeticRule struct {
+	text    string
+	sel     cascadia.Selector
+	exclude []string
+
+	// key is the id ("#x") or class (".x") the selector starts with, if
+	// any; documents without it cannot match.
+	key string
+}
+
+// cosmeticIndex holds element-hiding rules and their exceptions.
+type cosmeticIndex struct {
+	generic  []*cosmeticRule
+	byDomain map[string][]*cosmeticRule
+
+	// exceptions maps a selector to the domains where it is disabled;
+	// a "" entry disables it everywhere.
+	exceptions map[string][]string
+}
+
+func newCosmeticIndex() cosmeticIndex {
+	return cosmeticIndex{
+		byDomain:   make(map[string][]*cosmeticRule),
+		exceptions: make(map[string][]string),
+	}
+}
+
+// add compiles the cosmetic rule in line whose marker starts at offset i.
+func (ix *cosmeticIndex) add(line string, i int) bool {
+	domains := strings.ToLower(line[:i])
+	var body string
+	var exception bool
+	switch {
+	case strings.HasPrefix(line[i:], "##"):
+		body = line[i+2:]
+	case strings.HasPrefix(line[i:], "#@#"):
+		body, exception = line[i+3:], true
+	default:
+		return false
+	}
+	body = strings.TrimSpace(body)
+	if body == "" || strings.HasPrefix(body, "+js(") {
+		return false
+	}
+
+	var include, exclude []string
+	for _, d := range strings.Split(domains, ",") {
+		d = strings.TrimSpace(d)
+		if strings.HasPrefix(d, "~") {
+			exclude = append(exclude, d[1:])
+		} else if d != "" {
+			include = append(include, d)
+		}
+	}
+
+	if exception {
+		if len(include) == 0 {
+			include = []string{""}
+		}
+		ix.exceptions[body] = append(ix.exceptions[body], include...)
+		return true
+	}
+
+	sel, err := cascadia.Compile(body)
+	if err != nil {
+		return false
+	}
+	rule := &cosmeticRule{text: body, sel: sel, exclude: exclude, key: selectorKey(body)}
+	if len(include) == 0 {
+		ix.generic = append(ix.generic, rule)
+		return true
+	}
+	for _, d := range include {
+		ix.byDomain[d] = append(ix.byDomain[d], rule)
+	}
+	return true
+}
+
+// selectorKey returns the leading "#id" or ".class" of a single selector,
+// or "" when the selector does not start with one.
+func selectorKey(sel string) string {
+	if len(sel) < 2 || (sel[0] != '#' && sel[0] != '.') || strings.Contains(sel, ",") {
+		return ""
+	}
+	n := 1
+	for n < len(sel) && isIdentChar(sel[n]) {
+		n++
+	}
+	if n == 1 || (n < len(sel) && strings.IndexByte(" .#[:>+~", sel[n]) < 0) {
+		return ""
+	}
+	return sel[:n]
+}
+
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+// HiddenElements returns the elements of the document rooted at root that
+// the element-hiding rules for host hide, in no particular order.
+func (e *Engine) HiddenElements(root *html.Node, host string) []*html.Node {
+	if e == nil {
+		return nil
+	}
+	ix := &e.cosmetics
+	host = strings.ToLower(host)
+	keys := documentKeys(root)
+
+	var rules []*cosmeticRule
+	for _, r := range ix.generic {
+		if r.key == "" || keys[r.key] {
+			rules = append(rules, r)
+		}
+	}
+	for h := host; h != ""; {
+		rules = append(rules, ix.byDomain[h]...)
+		i := strings.IndexByte(h, '.')
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+
+	seen := make(map[*html.Node]struct{})
+	var hidden []*html.Node
+	for _, r := range rules {
+		if !ix.applies(r, host) {
+			continue
+		}
+		for _, n := range r.sel.MatchAll(root) {
+			if _, ok := seen[n]; !ok {
+				seen[n] = struct{}{}
+				hidden = append(hidden, n)
+			}
+		}
+	}
+	return hidden
+}
+
+// applies checks a rule's excluded domains and exceptions against host.
+func (ix *cosmeticIndex) applies(r *cosmeticRule, host string) bool {
+	for _, d := range r.exclude {
+		if hostMatches(host, d) {
+			return false
+		}
+	}
+	for _, d := range ix.exceptions[r.text] {
+		if d == "" || hostMatches(host, d) {
+			return false
+		}
+	}
+	return true
+}
+
+// documentKeys collects the "#id" and ".class" keys present in a document.
+func documentKeys(root *html.Node) map[string]bool {
+	keys := make(map[string]bool)
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, a := range n.Attr {
+				switch a.Key {
+				case "id":
+					keys["#"+a.Val] = true
+				case "class":
+					for _, c := range strings.Fields(a.Val) {
+						keys["."+c] = true
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return keys
+}
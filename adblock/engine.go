@@ -0,0 +1,190 @@
+// Package adblock implements a filter-list engine for Adblock Plus syntax
+// (EasyList, EasyPrivacy, uBlock Origin lists).
+//
+// Supported network filters: "||" domain anchors, "|" start/end anchors,
+// "*" wildcards, "^" separators, /regex/ patterns, "@@" exceptions and the
+// options third-party (3p) / first-party (1p), resource types (script,
+// image, stylesheet, xmlhttprequest, subdocument, font, media, websocket,
+// ping, object, other, with "~" negation), domain= and match-case.
+// Supported cosmetic filters: "##" element hiding, optionally restricted
+// to (or excluding, with "~") domains, and "#@#" exceptions.
+//
+// Rules using anything else (redirects, scriptlets, CSP injection,
+// procedural cosmetic filters) are skipped.
+package adblock
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Engine holds compiled filter rules. It is safe for concurrent use once
+// loading has finished.
+type Engine struct {
+	block     ruleIndex
+	allow     ruleIndex
+	cosmetics cosmeticIndex
+
+	// Rules is the number of network and cosmetic rules compiled.
+	Rules int
+	// Skipped is the number of rules ignored as unsupported.
+	Skipped int
+}
+
+// New returns an empty engine.
+func New() *Engine {
+	return &Engine{
+		block:     newRuleIndex(),
+		allow:     newRuleIndex(),
+		cosmetics: newCosmeticIndex(),
+	}
+}
+
+// LoadFiles compiles the filter lists at the given paths into one engine.
+func LoadFiles(paths ...string) (*Engine, error) {
+	e := New()
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		err = e.Load(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return e, nil
+}
+
+// Load adds the rules of a filter list, one rule per line.
+func (e *Engine) Load(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		e.AddRule(sc.Text())
+	}
+	return sc.Err()
+}
+
+// AddRule compiles a single filter line. Comments, blank lines and
+// unsupported rules are ignored.
+func (e *Engine) AddRule(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || line[0] == '!' || line[0] == '[' {
+		return
+	}
+
+	if i := cosmeticSeparator(line); i >= 0 {
+		if e.cosmetics.add(line, i) {
+			e.Rules++
+		} else {
+			e.Skipped++
+		}
+		return
+	}
+
+	exception := strings.HasPrefix(line, "@@")
+	if exception {
+		line = line[2:]
+	}
+	rule, ok := parseNetworkRule(line)
+	if !ok {
+		e.Skipped++
+		return
+	}
+	if exception {
+		e.allow.add(rule)
+	} else {
+		e.block.add(rule)
+	}
+	e.Rules++
+}
+
+// Request describes a network request for matching.
+type Request struct {
+	// URL is the full request URL.
+	URL string
+	// Type is the resource type of the request.
+	Type ResourceType
+	// SourceHost is the host of the page that issued the request; it
+	// decides third-party and domain= options.
+	SourceHost string
+}
+
+// Match reports whether the request is blocked: a blocking rule matches
+// and no exception rule does.
+func (e *Engine) Match(req Request) bool {
+	if e == nil {
+		return false
+	}
+	m, ok := newMatchRequest(req)
+	if !ok {
+		return false
+	}
+	return e.block.match(m) && !e.allow.match(m)
+}
+
+// matchRequest is a Request with the derived fields rules need.
+type matchRequest struct {
+	url        string // lowercased
+	rawURL     string // original case, for match-case rules
+	host       string
+	hostStart  int // offset of host in url
+	typ        ResourceType
+	sourceHost string
+	thirdParty bool
+	tokens     []string
+}
+
+func newMatchRequest(req Request) (matchRequest, bool) {
+	lower := strings.ToLower(req.URL)
+	scheme := strings.Index(lower, "://")
+	if scheme < 0 {
+		return matchRequest{}, false
+	}
+	hostStart := scheme + 3
+	hostEnd := len(lower)
+	if i := strings.IndexAny(lower[hostStart:], "/?#"); i >= 0 {
+		hostEnd = hostStart + i
+	}
+	host := lower[hostStart:hostEnd]
+	if at := strings.LastIndexByte(host, '@'); at >= 0 {
+		hostStart += at + 1
+		host = host[at+1:]
+	}
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.HasSuffix(host, "]") {
+		host = host[:i]
+	}
+
+	source := strings.ToLower(req.SourceHost)
+	return matchRequest{
+		url:        lower,
+		rawURL:     req.URL,
+		host:       host,
+		hostStart:  hostStart,
+		typ:        req.Type,
+		sourceHost: source,
+		thirdParty: source != "" && registrableDomain(host) != registrableDomain(source),
+		tokens:     urlTokens(lower),
+	}, true
+}
+
+// registrableDomain returns eTLD+1 for host, or host itself when it has
+// none (IP addresses, localhost).
+func registrableDomain(host string) string {
+	if d, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return d
+	}
+	return host
+}
+
+// hostMatches reports whether host equals domain or is a subdomain of it.
+func hostMatches(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,405 @@
+package adblock
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ResourceType is a request type as understood by filter options. Values
+// are bit flags so a rule can carry a set of them.
+type ResourceType uint16
+
+const (
+	TypeOther ResourceType = 1 << iota
+	TypeScript
+	TypeImage
+	TypeStylesheet
+	TypeXHR
+	TypeSubdocument
+	TypeFont
+	TypeMedia
+	TypeWebSocket
+	TypePing
+	TypeObject
+
+	allTypes = TypeObject<<1 - 1
+)
+
+// typeOptions maps filter option names (ABP and uBlock aliases) to types.
+var typeOptions = map[string]ResourceType{
+	"other":          TypeOther,
+	"script":         TypeScript,
+	"image":          TypeImage,
+	"stylesheet":     TypeStylesheet,
+	"css":            TypeStylesheet,
+	"xmlhttprequest": TypeXHR,
+	"xhr":            TypeXHR,
+	"subdocument":    TypeSubdocument,
+	"frame":          TypeSubdocument,
+	"font":           TypeFont,
+	"media":          TypeMedia,
+	"websocket":      TypeWebSocket,
+	"ping":           TypePing,
+	"beacon":         TypePing,
+	"object":         TypeObject,
+}
+
+// networkRule is a compiled URL filter.
+type networkRule struct {
+	// pattern is the wildcard pattern, with implicit leading/trailing "*"
+	// already added for unanchored ends. For host-indexed rules it covers
+	// only the part of the URL after host.
+	pattern string
+	re      *regexp.Regexp
+
+	host       string // set for rules indexed by domain anchor
+	hostAnchor bool   // "||" rule not indexed by host
+
+	types     ResourceType
+	party     int // 1: third-party only, -1: first-party only
+	include   []string
+	exclude   []string
+	matchCase bool
+}
+
+// parseNetworkRule compiles a URL filter (without its "@@" prefix).
+// Returns false for rules using unsupported syntax or options.
+func parseNetworkRule(line string) (*networkRule, bool) {
+	pattern, options := line, ""
+	isRegex := false
+	if strings.HasPrefix(line, "/") {
+		// /regex/ or /regex/$options: the pattern may itself contain "$".
+		if end := strings.LastIndexByte(line, '/'); end > 0 {
+			if rest := line[end+1:]; rest == "" || rest[0] == '$' {
+				pattern, options = line[:end+1], strings.TrimPrefix(rest, "$")
+				isRegex = true
+			}
+		}
+	}
+	if i := strings.LastIndexByte(line, '$'); !isRegex && i >= 0 {
+		pattern, options = line[:i], line[i+1:]
+	}
+
+	r := &networkRule{types: allTypes}
+	if !r.parseOptions(options) {
+		return nil, false
+	}
+
+	if len(pattern) > 2 && pattern[0] == '/' && pattern[len(pattern)-1] == '/' {
+		expr := pattern[1 : len(pattern)-1]
+		if !r.matchCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, false
+		}
+		r.re = re
+		return r, true
+	}
+
+	if !r.matchCase {
+		pattern = strings.ToLower(pattern)
+	}
+	startAnchor := false
+	switch {
+	case strings.HasPrefix(pattern, "||"):
+		r.hostAnchor = true
+		pattern = pattern[2:]
+	case strings.HasPrefix(pattern, "|"):
+		startAnchor = true
+		pattern = pattern[1:]
+	}
+	endAnchor := strings.HasSuffix(pattern, "|")
+	if endAnchor {
+		pattern = pattern[:len(pattern)-1]
+	}
+	if strings.ContainsAny(pattern, "|") {
+		return nil, false
+	}
+
+	// "||example.com^..." is indexed by its host so lookups only consider
+	// rules for the request's domain and its parents.
+	if r.hostAnchor {
+		n := 0
+		for n < len(pattern) && isHostChar(pattern[n]) {
+			n++
+		}
+		if n > 0 && n < len(pattern) && strings.IndexByte("^/:", pattern[n]) >= 0 {
+			r.host = strings.ToLower(strings.TrimSuffix(pattern[:n], "."))
+			r.hostAnchor = false
+			pattern = pattern[n:]
+			startAnchor = true
+		}
+	}
+
+	if !startAnchor && !r.hostAnchor {
+		pattern = "*" + pattern
+	}
+	if !endAnchor {
+		pattern += "*"
+	}
+	r.pattern = pattern
+	return r, true
+}
+
+// parseOptions applies the comma-separated options after "$".
+func (r *networkRule) parseOptions(options string) bool {
+	if options == "" {
+		return true
+	}
+	var include, exclude ResourceType
+	for _, opt := range strings.Split(options, ",") {
+		opt = strings.TrimSpace(strings.ToLower(opt))
+		neg := strings.HasPrefix(opt, "~")
+		name := strings.TrimPrefix(opt, "~")
+
+		switch {
+		case name == "third-party" || name == "3p":
+			r.party = 1
+			if neg {
+				r.party = -1
+			}
+		case name == "first-party" || name == "1p":
+			r.party = -1
+			if neg {
+				r.party = 1
+			}
+		case name == "match-case":
+			r.matchCase = true
+		case name == "important":
+			// Exceptions still apply; treated as a normal rule.
+		case strings.HasPrefix(name, "domain=") && !neg:
+			for _, d := range strings.Split(name[len("domain="):], "|") {
+				if strings.HasPrefix(d, "~") {
+					r.exclude = append(r.exclude, d[1:])
+				} else if d != "" {
+					r.include = append(r.include, d)
+				}
+			}
+		default:
+			t, ok := typeOptions[name]
+			if !ok {
+				return false
+			}
+			if neg {
+				exclude |= t
+			} else {
+				include |= t
+			}
+		}
+	}
+	if include != 0 {
+		r.types = include
+	}
+	r.types &^= exclude
+	return r.types != 0
+}
+
+// matches applies the rule to a request.
+func (r *networkRule) matches(m *matchRequest) bool {
+	typ := m.typ
+	if typ == 0 {
+		typ = TypeOther
+	}
+	if r.types&typ == 0 {
+		return false
+	}
+	if (r.party == 1 && !m.thirdParty) || (r.party == -1 && m.thirdParty) {
+		return false
+	}
+	if !r.domainAllowed(m.sourceHost) {
+		return false
+	}
+
+	subject := m.url
+	if r.matchCase && len(m.rawURL) == len(m.url) {
+		subject = m.rawURL
+	}
+	switch {
+	case r.re != nil:
+		return r.re.MatchString(m.rawURL)
+	case r.host != "":
+		return hostMatches(m.host, r.host) && wildcardMatch(r.pattern, subject[m.hostStart+len(m.host):])
+	case r.hostAnchor:
+		// The pattern may start at the host or after any dot in it.
+		if wildcardMatch(r.pattern, subject[m.hostStart:]) {
+			return true
+		}
+		for i := 0; i < len(m.host); i++ {
+			if m.host[i] == '.' && wildcardMatch(r.pattern, subject[m.hostStart+i+1:]) {
+				return true
+			}
+		}
+		return false
+	default:
+		return wildcardMatch(r.pattern, subject)
+	}
+}
+
+// domainAllowed applies the domain= option to the source page host.
+func (r *networkRule) domainAllowed(source string) bool {
+	for _, d := range r.exclude {
+		if hostMatches(source, d) {
+			return false
+		}
+	}
+	if len(r.include) == 0 {
+		return true
+	}
+	for _, d := range r.include {
+		if hostMatches(source, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleIndex buckets network rules so a request is only tested against
+// rules that could match it.
+type ruleIndex struct {
+	byHost  map[string][]*networkRule
+	byToken map[string][]*networkRule
+	generic []*networkRule
+}
+
+func newRuleIndex() ruleIndex {
+	return ruleIndex{
+		byHost:  make(map[string][]*networkRule),
+		byToken: make(map[string][]*networkRule),
+	}
+}
+
+func (ix *ruleIndex) add(r *networkRule) {
+	if r.host != "" {
+		ix.byHost[r.host] = append(ix.byHost[r.host], r)
+		return
+	}
+	if r.re == nil {
+		if tok := ruleToken(r.pattern); tok != "" {
+			ix.byToken[tok] = append(ix.byToken[tok], r)
+			return
+		}
+	}
+	ix.generic = append(ix.generic, r)
+}
+
+// match reports whether any rule in the index matches the request.
+func (ix *ruleIndex) match(m matchRequest) bool {
+	for h := m.host; h != ""; {
+		for _, r := range ix.byHost[h] {
+			if r.matches(&m) {
+				return true
+			}
+		}
+		i := strings.IndexByte(h, '.')
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+	for _, tok := range m.tokens {
+		for _, r := range ix.byToken[tok] {
+			if r.matches(&m) {
+				return true
+			}
+		}
+	}
+	for _, r := range ix.generic {
+		if r.matches(&m) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleToken picks the longest run of token characters in a pattern that is
+// guaranteed to appear as a whole token in any matching URL, i.e. is not
+// next to a wildcard. Returns "" when there is none.
+func ruleToken(pattern string) string {
+	best := ""
+	for i := 0; i < len(pattern); {
+		if !isTokenChar(pattern[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(pattern) && isTokenChar(pattern[i]) {
+			i++
+		}
+		// Unanchored starts and ends are encoded as "*", so the pattern
+		// edges themselves are token boundaries.
+		if start > 0 && pattern[start-1] == '*' || i < len(pattern) && pattern[i] == '*' {
+			continue
+		}
+		if i-start > len(best) {
+			best = strings.ToLower(pattern[start:i])
+		}
+	}
+	return best
+}
+
+// urlTokens splits a lowercased URL into its distinct tokens.
+func urlTokens(u string) []string {
+	seen := make(map[string]struct{})
+	var tokens []string
+	for i := 0; i < len(u); {
+		if !isTokenChar(u[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(u) && isTokenChar(u[i]) {
+			i++
+		}
+		tok := u[start:i]
+		if _, ok := seen[tok]; !ok {
+			seen[tok] = struct{}{}
+			tokens = append(tokens, tok)
+		}
+	}
+	return tokens
+}
+
+// wildcardMatch matches s against a filter pattern where "*" matches any
+// run of characters and "^" matches a separator character or the end of s.
+func wildcardMatch(p, s string) bool {
+	pi, si := 0, 0
+	star, mark := -1, 0
+	for si < len(s) {
+		switch {
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, si
+			pi++
+		case pi < len(p) && (p[pi] == s[si] || p[pi] == '^' && isSeparator(s[si])):
+			pi++
+			si++
+		case star >= 0:
+			mark++
+			si = mark
+			pi = star + 1
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && (p[pi] == '*' || p[pi] == '^') {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// isSeparator reports whether c matches "^": anything but a letter, digit
+// or one of "_-.%".
+func isSeparator(c byte) bool {
+	return !isTokenChar(c) && c != '_' && c != '-' && c != '.'
+}
+
+// isTokenChar reports whether c belongs to a URL token.
+func isTokenChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '%'
+}
+
+// isHostChar reports whether c may appear in a host name.
+func isHostChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_'
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"sync"
@@ -14,6 +15,7 @@ import (
 	"github.com/use-agent/purify/cleaner"
 	"github.com/use-agent/purify/models"
 	"github.com/use-agent/purify/scraper"
+	"github.com/use-agent/purify/simhash"
 	"github.com/use-agent/purify/webhook"
 )
 
@@ -195,6 +197,8 @@ func scrapeOne(sc *scraper.Scraper, cl *cleaner.Cleaner, targetURL string, opts
 		WaitForNetworkIdle: opts.WaitForNetworkIdle,
 		Timeout:            opts.Timeout,
 		Stealth:            opts.Stealth,
+		Chunking:           opts.Chunking,
+		Tokenizer:          opts.Tokenizer,
 	}
 	sreq.Defaults()
 
@@ -247,6 +251,12 @@ func scrapeOne(sc *scraper.Scraper, cl *cleaner.Cleaner, targetURL string, opts
 	resp.StatusCode = result.StatusCode
 	resp.FinalURL = result.FinalURL
 	resp.EngineUsed = result.EngineUsed
+	resp.Debug = debugInfo(result)
+	countTokens(resp, result, sreq.Tokenizer)
+	if sreq.Chunking != nil {
+		resp.Chunks = cleaner.Chunk(resp.Content, *sreq.Chunking, sreq.Tokenizer)
+	}
+	resp.Fingerprint = contentFingerprint(resp.Content)
 	resp.Timing = models.TimingInfo{
 		TotalMs:      time.Since(totalStart).Milliseconds(),
 		NavigationMs: navigationMs,
@@ -256,6 +266,16 @@ func scrapeOne(sc *scraper.Scraper, cl *cleaner.Cleaner, targetURL string, opts
 	return resp
 }
 
+// contentFingerprint returns the script-aware SimHash of cleaned content
+// (see simhash.FingerprintWith) as 16 hex digits, or "" for empty content.
+func contentFingerprint(content string) string {
+	fp := simhash.FingerprintWith(content, simhash.Options{})
+	if fp == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%016x", fp)
+}
+
 // randomID generates a short random hex string for job IDs.
 func randomID() string {
 	b := make([]byte, 8)
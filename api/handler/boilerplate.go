@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"math"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/use-agent/purify/cleaner"
+	"github.com/use-agent/purify/models"
+)
+
+// boilerplateTTL is how long a site's learned boilerplate is kept.
+const boilerplateTTL = 24 * time.Hour
+
+// boilerplateStore maps a lowercased host to the boilerplate learned by
+// its most recent crawl.
+var boilerplateStore sync.Map
+
+// boilerplateEntry is a host's learned boilerplate.
+type boilerplateEntry struct {
+	templates cleaner.Boilerplate
+	learnedAt time.Time
+}
+
+func init() {
+	// Background goroutine to expire learned boilerplate.
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			cutoff := time.Now().Add(-boilerplateTTL)
+			boilerplateStore.Range(func(key, value any) bool {
+				if value.(*boilerplateEntry).learnedAt.Before(cutoff) {
+					boilerplateStore.Delete(key)
+				}
+				return true
+			})
+		}
+	}()
+}
+
+// pageHost returns the lowercased host of the page a response came from.
+func pageHost(resp *models.ScrapeResponse, requestURL string) string {
+	raw := resp.FinalURL
+	if raw == "" {
+		raw = requestURL
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// learnedBoilerplate returns the boilerplate cached for host, if any.
+func learnedBoilerplate(host string) cleaner.Boilerplate {
+	if val, ok := boilerplateStore.Load(host); ok {
+		return val.(*boilerplateEntry).templates
+	}
+	return nil
+}
+
+// stripBoilerplate removes template blocks from a successful response and
+// refreshes the token counts and chunks that depend on the content.
+func stripBoilerplate(resp *models.ScrapeResponse, tpl cleaner.Boilerplate, format string, chunking *models.ChunkingOptions, tokenizer string) {
+	if !resp.Success {
+		return
+	}
+	content, removed := tpl.Strip(resp.Content, format)
+	if removed == 0 {
+		return
+	}
+	resp.Content = content
+	updateEstimate(&resp.Tokens, content)
+	if resp.Tokens.Tokenizer != "" {
+		resp.Tokens.CleanedCount = cleaner.CountTokens(content, resp.Tokens.Tokenizer)
+		if orig := resp.Tokens.OriginalCount; orig > 0 {
+			savings := float64(orig-resp.Tokens.CleanedCount) / float64(orig) * 100
+			resp.Tokens.SavingsPercent = math.Round(savings*100) / 100
+		}
+	}
+	if chunking != nil {
+		resp.Chunks = cleaner.Chunk(content, *chunking, tokenizer)
+	}
+	if resp.Fingerprint != "" {
+		resp.Fingerprint = contentFingerprint(content)
+	}
+}
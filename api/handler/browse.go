@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gorilla/websocket"
+	"github.com/use-agent/purify/cleaner"
+	"github.com/use-agent/purify/config"
+	"github.com/use-agent/purify/models"
+	"github.com/use-agent/purify/scraper"
+)
+
+// browseWriteTimeout bounds a single WebSocket write.
+const browseWriteTimeout = 10 * time.Second
+
+// browseUpgrader accepts any origin: the endpoint is authenticated by API
+// key, not by cookies.
+var browseUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 64 * 1024,
+	CheckOrigin:     func(*http.Request) bool { return true },
+}
+
+// Browse handles GET /api/v1/browse: a WebSocket that holds a pooled
+// browser tab open and runs the client's commands against it.
+//
+// Protocol:
+//
+//  1. The server sends "ready" once the tab is acquired.
+//  2. The client sends BrowseCommand messages; each is answered by a
+//     "result" or "error" event carrying the command id. Commands run one
+//     at a time, in order.
+//  3. Page events ("navigated", "console", "dialog") stream in between.
+//  4. The session ends with a "closed" event on a close command, after
+//     IdleTimeout without commands, or when the client disconnects.
+//
+// Query parameters: stealth (default true), dialogs ("accept" or
+// "dismiss", default "dismiss").
+func Browse(sc *scraper.Scraper, cl *cleaner.Cleaner, cfg config.BrowseConfig) gin.HandlerFunc {
+	// Sessions hold pool tabs for minutes; keep at least one tab free for
+	// /scrape, /batch and /crawl whatever MaxSessions says.
+	maxTotal := cfg.MaxSessions
+	if pool := sc.Stats().MaxPages; pool > 0 && (maxTotal <= 0 || maxTotal >= pool) {
+		maxTotal = max(pool-1, 0)
+	}
+	limits := newSessionLimits(cfg.MaxSessionsPerKey, maxTotal)
+
+	return func(c *gin.Context) {
+		// ── 1. Global and per-key session limits ────────────────────
+		identity := c.GetString("api_key")
+		if identity == "" {
+			identity = c.ClientIP()
+		}
+		if err := limits.acquire(identity); err != nil {
+			c.JSON(http.StatusTooManyRequests, models.ScrapeResponse{
+				Success: false,
+				Error: &models.ErrorDetail{
+					Code:    models.ErrCodeRateLimited,
+					Message: err.Error(),
+				},
+			})
+			return
+		}
+		defer limits.release(identity)
+
+		opts := scraper.SessionOptions{
+			Stealth: c.DefaultQuery("stealth", "true") != "false",
+			Dialogs: c.Query("dialogs"),
+		}
+		if opts.Dialogs != "" && opts.Dialogs != "accept" && opts.Dialogs != "dismiss" {
+			c.JSON(http.StatusBadRequest, models.ScrapeResponse{
+				Success: false,
+				Error: &models.ErrorDetail{
+					Code:    models.ErrCodeInvalidInput,
+					Message: `dialogs must be "accept" or "dismiss"`,
+				},
+			})
+			return
+		}
+
+		// ── 2. Upgrade and open the session ─────────────────────────
+		conn, err := browseUpgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			// The upgrader has already written an HTTP error.
+			return
+		}
+		defer conn.Close()
+		bc := &browseConn{conn: conn}
+
+		sess, err := sc.OpenSession(opts, bc.pageEvent)
+		if err != nil {
+			bc.sendError("", "", err)
+			bc.send(models.BrowseEvent{Type: "closed", Reason: "error"})
+			return
+		}
+		defer sess.Close()
+
+		sessionID := "browse-" + randomID()
+		slog.Info("browse session opened", "session", sessionID)
+		defer slog.Info("browse session closed", "session", sessionID)
+		bc.send(models.BrowseEvent{Type: "ready", SessionID: sessionID})
+
+		// ── 3. Command loop ─────────────────────────────────────────
+		ctx := c.Request.Context()
+		for {
+			_ = conn.SetReadDeadline(time.Now().Add(cfg.IdleTimeout))
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					bc.send(models.BrowseEvent{Type: "closed", Reason: "idle_timeout"})
+				}
+				return
+			}
+
+			var cmd models.BrowseCommand
+			if err := json.Unmarshal(msg, &cmd); err == nil {
+				err = binding.Validator.ValidateStruct(&cmd)
+			}
+			if err != nil {
+				bc.sendError(cmd.ID, cmd.Type, models.NewScrapeError(models.ErrCodeInvalidInput, err.Error(), err))
+				continue
+			}
+			if cmd.Type == "close" {
+				bc.send(models.BrowseEvent{Type: "closed", ID: cmd.ID, Reason: "client"})
+				return
+			}
+
+			ev, err := runBrowseCommand(ctx, sess, cl, &cmd)
+			if err != nil {
+				bc.sendError(cmd.ID, cmd.Type, err)
+				continue
+			}
+			ev.Type, ev.ID, ev.Command = "result", cmd.ID, cmd.Type
+			bc.send(*ev)
+		}
+	}
+}
+
+// runBrowseCommand executes one command against the session.
+func runBrowseCommand(ctx context.Context, sess *scraper.Session, cl *cleaner.Cleaner, cmd *models.BrowseCommand) (*models.BrowseEvent, error) {
+	timeout := 30 * time.Second
+	if cmd.Timeout > 0 {
+		timeout = time.Duration(cmd.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch cmd.Type {
+	case "navigate":
+		if cmd.URL == "" {
+			return nil, models.NewScrapeError(models.ErrCodeInvalidInput, "navigate requires a url", nil)
+		}
+		state, err := sess.Navigate(ctx, cmd.URL)
+		if err != nil {
+			return nil, err
+		}
+		return stateEvent(state), nil
+
+	case "actions":
+		if len(cmd.Actions) == 0 {
+			return nil, models.NewScrapeError(models.ErrCodeInvalidInput, "actions requires at least one action", nil)
+		}
+		state, err := sess.Run(ctx, cmd.Actions)
+		if err != nil {
+			return nil, err
+		}
+		return stateEvent(state), nil
+
+	case "snapshot":
+		return browseSnapshot(ctx, sess, cl, cmd)
+
+	case "screenshot":
+		img, err := sess.Screenshot(ctx, cmd.FullPage, cmd.ImageFormat, cmd.Quality)
+		if err != nil {
+			return nil, err
+		}
+		ev := stateEvent(sess.State(ctx))
+		ev.Screenshot = base64.StdEncoding.EncodeToString(img)
+		ev.MIMEType = "image/png"
+		if cmd.ImageFormat == "jpeg" {
+			ev.MIMEType = "image/jpeg"
+		}
+		return ev, nil
+	}
+	return nil, models.NewScrapeError(models.ErrCodeInvalidInput, "unknown command "+cmd.Type, nil)
+}
+
+// browseSnapshot captures the current page as cleaned content or as its
+// accessibility tree.
+func browseSnapshot(ctx context.Context, sess *scraper.Session, cl *cleaner.Cleaner, cmd *models.BrowseCommand) (*models.BrowseEvent, error) {
+	format := cmd.Format
+	if format == "" {
+		format = "markdown"
+	}
+	mode := cmd.ExtractMode
+	if mode == "" {
+		mode = "readability"
+	}
+
+	ev := stateEvent(sess.State(ctx))
+	html, err := sess.HTML(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if format == "accessibility" {
+		tree, err := sess.Accessibility(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ev.Content = tree
+		ev.Tokens = &models.TokenInfo{
+			OriginalEstimate: cleaner.EstimateTokens(html),
+			CleanedEstimate:  cleaner.EstimateTokens(tree),
+		}
+	} else {
+		resp, err := cl.Clean(html, ev.URL, format, mode)
+		if err != nil {
+			return nil, err
+		}
+		ev.Content = resp.Content
+		ev.Tokens = &resp.Tokens
+		if resp.Metadata.Title != "" {
+			ev.Title = resp.Metadata.Title
+		}
+	}
+	if cmd.InteractiveElements {
+		ev.InteractiveElements = sess.InteractiveElements(ctx)
+	}
+	return ev, nil
+}
+
+func stateEvent(state scraper.PageState) *models.BrowseEvent {
+	return &models.BrowseEvent{URL: state.URL, Title: state.Title, StatusCode: state.StatusCode}
+}
+
+// browseConn serializes writes to a WebSocket shared by the command loop
+// and the page event listener.
+type browseConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (bc *browseConn) send(ev models.BrowseEvent) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	_ = bc.conn.SetWriteDeadline(time.Now().Add(browseWriteTimeout))
+	if err := bc.conn.WriteJSON(ev); err != nil {
+		slog.Debug("browse: write failed", "event", ev.Type, "error", err)
+	}
+}
+
+func (bc *browseConn) sendError(id, command string, err error) {
+	scrapeErr, ok := err.(*models.ScrapeError)
+	if !ok {
+		scrapeErr = models.NewScrapeError(models.ErrCodeInternal, err.Error(), err)
+	}
+	bc.send(models.BrowseEvent{Type: "error", ID: id, Command: command, Error: scrapeErr.ToDetail()})
+}
+
+// pageEvent forwards a session's page event to the client.
+func (bc *browseConn) pageEvent(e scraper.SessionEvent) {
+	bc.send(models.BrowseEvent{
+		Type:    e.Type,
+		URL:     e.URL,
+		Level:   e.Level,
+		Dialog:  e.Dialog,
+		Message: e.Message,
+	})
+}
+
+// sessionLimits counts open sessions, in total and per identity.
+type sessionLimits struct {
+	max      int // per identity; 0 means unlimited
+	maxTotal int
+	mu       sync.Mutex
+	total    int
+	open     map[string]int
+}
+
+func newSessionLimits(max, maxTotal int) *sessionLimits {
+	return &sessionLimits{max: max, maxTotal: maxTotal, open: make(map[string]int)}
+}
+
+// acquire reserves a session for identity, or reports the limit reached.
+func (l *sessionLimits) acquire(identity string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.total >= l.maxTotal {
+		return fmt.Errorf("too many open browse sessions (max %d)", l.maxTotal)
+	}
+	if l.max > 0 && l.open[identity] >= l.max {
+		return fmt.Errorf("too many open browse sessions (max %d per key)", l.max)
+	}
+	l.open[identity]++
+	l.total++
+	return nil
+}
+
+func (l *sessionLimits) release(identity string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total--
+	if l.open[identity]--; l.open[identity] <= 0 {
+		delete(l.open, identity)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/use-agent/purify/cleaner"
 	"github.com/use-agent/purify/models"
 	"github.com/use-agent/purify/scraper"
+	"github.com/use-agent/purify/simhash"
 	"github.com/use-agent/purify/webhook"
 )
 
@@ -64,6 +66,12 @@ func PostCrawl(sc *scraper.Scraper, cl *cleaner.Cleaner) gin.HandlerFunc {
 		if req.Options.ExtractMode == "" {
 			req.Options.ExtractMode = "readability"
 		}
+		if req.DedupeThreshold == 0 {
+			req.DedupeThreshold = 3
+		}
+		if req.BoilerplateThreshold == 0 {
+			req.BoilerplateThreshold = 50
+		}
 
 		jobID := "crawl-" + randomID()
 		job := &models.CrawlJob{
@@ -138,6 +146,13 @@ func runCrawl(sc *scraper.Scraper, cl *cleaner.Cleaner, job *models.CrawlJob, re
 	var results []*models.ScrapeResponse
 	var totalPages int
 
+	// Fingerprints of the distinct pages crawled so far, for dedupe.
+	seen := simhash.NewIndex(req.DedupeThreshold)
+
+	// Boilerplate learners per host, and the host of each result.
+	learners := make(map[string]*cleaner.BoilerplateLearner)
+	hosts := make(map[*models.ScrapeResponse]string)
+
 	queue := []bfsItem{{url: req.URL, depth: 0}}
 
 	for len(queue) > 0 {
@@ -176,11 +191,39 @@ func runCrawl(sc *scraper.Scraper, cl *cleaner.Cleaner, job *models.CrawlJob, re
 				opts := models.BatchOptions{
 					OutputFormat: req.Options.OutputFormat,
 					ExtractMode:  req.Options.ExtractMode,
+					Chunking:     req.Options.Chunking,
+					Tokenizer:    req.Options.Tokenizer,
 				}
 
 				resp := scrapeOne(sc, cl, it.url, opts)
 
 				mu.Lock()
+				if req.Dedupe != "" && resp.Success {
+					if fp, err := strconv.ParseUint(resp.Fingerprint, 16, 64); err == nil {
+						if m, ok := seen.Nearest(fp); ok {
+							resp.DuplicateOf = m.ID
+						}
+						if resp.DuplicateOf == "" {
+							seen.Add(it.url, fp)
+						} else if req.Dedupe == "skip" {
+							resp = duplicateStub(resp)
+						}
+					}
+				}
+				if req.RemoveBoilerplate && resp.Success && resp.Content != "" {
+					// Learn from every distinct page; results are stripped
+					// once the crawl has seen them all.
+					host := pageHost(resp, it.url)
+					l := learners[host]
+					if l == nil {
+						l = cleaner.NewBoilerplateLearner()
+						learners[host] = l
+					}
+					if resp.DuplicateOf == "" {
+						l.Observe(resp.Content, opts.OutputFormat)
+					}
+					hosts[resp] = host
+				}
 				results = append(results, resp)
 				job.Completed = len(results)
 				job.Results = results
@@ -229,6 +272,21 @@ func runCrawl(sc *scraper.Scraper, cl *cleaner.Cleaner, job *models.CrawlJob, re
 	}
 
 	mu.Lock()
+	for host, l := range learners {
+		tpl := l.Templates(req.BoilerplateThreshold)
+		if tpl == nil {
+			continue
+		}
+		boilerplateStore.Store(host, &boilerplateEntry{templates: tpl, learnedAt: time.Now()})
+		for i, r := range results {
+			if hosts[r] == host {
+				// Strip a copy: per-page webhooks may still be sending r.
+				stripped := *r
+				stripBoilerplate(&stripped, tpl, req.Options.OutputFormat, req.Options.Chunking, req.Options.Tokenizer)
+				results[i] = &stripped
+			}
+		}
+	}
 	job.Total = len(results)
 	failedCount := 0
 	for _, r := range results {
@@ -271,6 +329,19 @@ func runCrawl(sc *scraper.Scraper, cl *cleaner.Cleaner, job *models.CrawlJob, re
 	}
 }
 
+// duplicateStub reduces a duplicate page's result to its metadata.
+func duplicateStub(resp *models.ScrapeResponse) *models.ScrapeResponse {
+	return &models.ScrapeResponse{
+		Success:     true,
+		StatusCode:  resp.StatusCode,
+		FinalURL:    resp.FinalURL,
+		Metadata:    resp.Metadata,
+		Fingerprint: resp.Fingerprint,
+		DuplicateOf: resp.DuplicateOf,
+		Timing:      resp.Timing,
+	}
+}
+
 // isInScope checks whether a link URL is within the crawl scope relative to the base URL.
 func isInScope(linkURL string, baseURL *url.URL, scope string) bool {
 	parsed, err := url.Parse(linkURL)
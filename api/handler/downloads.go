@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+
+	"github.com/use-agent/purify/cleaner"
+	"github.com/use-agent/purify/models"
+	"github.com/use-agent/purify/scraper"
+)
+
+// attachDownloads copies downloaded files into resp as base64 attachments
+// and, when ingestion is requested, extracts their content.
+func attachDownloads(cl *cleaner.Cleaner, req *models.ScrapeRequest, result *scraper.ScrapeResult, resp *models.ScrapeResponse) {
+	for _, d := range result.Downloads {
+		file := models.DownloadedFile{
+			Filename: d.Filename,
+			URL:      d.URL,
+			MIMEType: d.MIMEType,
+			Size:     d.Size,
+			Error:    d.Error,
+		}
+		if d.Data != nil {
+			file.Data = base64.StdEncoding.EncodeToString(d.Data)
+			if req.Downloads.Ingest {
+				content, err := cl.CleanDocument(d.Data, d.MIMEType, d.URL, req.OutputFormat)
+				switch {
+				case err == nil:
+					file.Content = content
+				case !errors.Is(err, cleaner.ErrUnsupportedDocument):
+					file.Error = "ingest failed: " + err.Error()
+				}
+			}
+		}
+		resp.Downloads = append(resp.Downloads, file)
+	}
+}
@@ -78,6 +78,7 @@ func Extract(sc *scraper.Scraper, cl *cleaner.Cleaner, llmClient *llm.Client) gi
 			scrapeResp.Metadata.Title = result.Title
 		}
 		scrapeResp.Metadata.FetchMethod = result.FetchMethod
+		applyMaxTokens(scrapeResp, req.MaxTokens, cleaner.TokenizerEstimate)
 
 		// ── 4. LLM Extract ──────────────────────────────────────────
 		extractStart := time.Now()
@@ -90,25 +91,27 @@ func Extract(sc *scraper.Scraper, cl *cleaner.Cleaner, llmClient *llm.Client) gi
 
 		if err != nil {
 			respondExtractError(c, err, models.ExtractTimingInfo{
-				TotalMs:        time.Since(totalStart).Milliseconds(),
-				NavigationMs:   navigationMs,
-				CleaningMs:     cleaningMs,
-				ExtractionMs:   extractionMs,
+				TotalMs:      time.Since(totalStart).Milliseconds(),
+				NavigationMs: navigationMs,
+				CleaningMs:   cleaningMs,
+				ExtractionMs: extractionMs,
 			})
 			return
 		}
 
 		// ── 5. Assemble response ────────────────────────────────────
 		c.JSON(http.StatusOK, models.ExtractResponse{
-			Success:  true,
-			Data:     llmResult.Data,
-			Metadata: scrapeResp.Metadata,
-			Tokens:   scrapeResp.Tokens,
+			Success:         true,
+			Data:            llmResult.Data,
+			Metadata:        scrapeResp.Metadata,
+			Tokens:          scrapeResp.Tokens,
+			Truncated:       scrapeResp.Truncated,
+			TruncatedTokens: scrapeResp.TruncatedTokens,
 			Timing: models.ExtractTimingInfo{
-				TotalMs:        time.Since(totalStart).Milliseconds(),
-				NavigationMs:   navigationMs,
-				CleaningMs:     cleaningMs,
-				ExtractionMs:   extractionMs,
+				TotalMs:      time.Since(totalStart).Milliseconds(),
+				NavigationMs: navigationMs,
+				CleaningMs:   cleaningMs,
+				ExtractionMs: extractionMs,
 			},
 			LLMUsage: llmResult.Usage,
 		})
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/use-agent/purify/cleaner"
+	"github.com/use-agent/purify/config"
+	"github.com/use-agent/purify/models"
+	"github.com/use-agent/purify/monitor"
+	"github.com/use-agent/purify/scraper"
+	"github.com/use-agent/purify/selector"
+)
+
+// MonitorCheck returns the monitor.CheckFunc that scrapes and cleans a
+// monitor's URL with its options and CSS selector.
+func MonitorCheck(sc *scraper.Scraper, cl *cleaner.Cleaner) monitor.CheckFunc {
+	return func(ctx context.Context, m *models.Monitor) (string, error) {
+		sreq := &models.ScrapeRequest{
+			URL:                m.URL,
+			OutputFormat:       m.Options.OutputFormat,
+			ExtractMode:        m.Options.ExtractMode,
+			WaitForNetworkIdle: m.Options.WaitForNetworkIdle,
+			Timeout:            m.Options.Timeout,
+			Stealth:            m.Options.Stealth,
+			CSSSelector:        m.CSSSelector,
+		}
+		sreq.Defaults()
+
+		result, err := sc.DoScrape(ctx, sreq)
+		if err != nil {
+			return "", err
+		}
+		resp, err := cl.Clean(result.RawHTML, sreq.URL, sreq.OutputFormat, sreq.ExtractMode, cleanOptions(sreq)...)
+		if err != nil {
+			return "", err
+		}
+		return resp.Content, nil
+	}
+}
+
+// PostMonitor returns a handler for POST /api/v1/monitors.
+func PostMonitor(mon *monitor.Scheduler, cfg config.MonitorConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.MonitorRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": models.ErrorDetail{
+					Code:    models.ErrCodeInvalidInput,
+					Message: err.Error(),
+				},
+			})
+			return
+		}
+
+		// Apply defaults.
+		if req.Interval == 0 {
+			req.Interval = 3600
+		}
+		if minInterval := int(cfg.MinInterval / time.Second); req.Interval < minInterval {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": models.ErrorDetail{
+					Code:    models.ErrCodeInvalidInput,
+					Message: fmt.Sprintf("interval must be at least %d seconds", minInterval),
+				},
+			})
+			return
+		}
+
+		if req.CSSSelector != "" {
+			if _, err := selector.Parse(req.CSSSelector); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": models.ErrorDetail{
+						Code:    models.ErrCodeInvalidInput,
+						Message: "css_selector: " + err.Error(),
+					},
+				})
+				return
+			}
+		}
+
+		m := &models.Monitor{
+			ID:            "monitor-" + randomID(),
+			URL:           req.URL,
+			Interval:      req.Interval,
+			Options:       req.Options,
+			CSSSelector:   req.CSSSelector,
+			Threshold:     req.Threshold,
+			WebhookURL:    req.WebhookURL,
+			CreatedAt:     time.Now().Unix(),
+			Owner:         monitorOwner(c),
+			WebhookSecret: req.WebhookSecret,
+		}
+		if !mon.Add(m) {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": models.ErrorDetail{
+					Code:    models.ErrCodeRateLimited,
+					Message: fmt.Sprintf("too many monitors (max %d per key)", cfg.MaxPerKey),
+				},
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, m)
+	}
+}
+
+// ListMonitors returns a handler for GET /api/v1/monitors.
+func ListMonitors(mon *monitor.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, models.MonitorListResponse{
+			Monitors: mon.List(monitorOwner(c)),
+		})
+	}
+}
+
+// GetMonitor returns a handler for GET /api/v1/monitors/:id.
+func GetMonitor(mon *monitor.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		m, ok := mon.Get(c.Param("id"))
+		if !ok || m.Owner != monitorOwner(c) {
+			monitorNotFound(c)
+			return
+		}
+		c.JSON(http.StatusOK, m)
+	}
+}
+
+// DeleteMonitor returns a handler for DELETE /api/v1/monitors/:id.
+func DeleteMonitor(mon *monitor.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		m, ok := mon.Get(id)
+		if !ok || m.Owner != monitorOwner(c) {
+			monitorNotFound(c)
+			return
+		}
+		mon.Remove(id)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// monitorOwner identifies the caller: its API key, or its IP when auth is
+// disabled. Monitors are only visible to their owner.
+func monitorOwner(c *gin.Context) string {
+	if key := c.GetString("api_key"); key != "" {
+		return key
+	}
+	return c.ClientIP()
+}
+
+func monitorNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, gin.H{
+		"error": models.ErrorDetail{
+			Code:    models.ErrCodeInvalidInput,
+			Message: "monitor not found",
+		},
+	})
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"log/slog"
+	"math"
+	"strings"
+
+	"github.com/use-agent/purify/cleaner"
+	"github.com/use-agent/purify/models"
+	"github.com/use-agent/purify/scraper"
+	"github.com/use-agent/purify/selector"
+)
+
+// checkPaginate rejects an invalid paginate.next_selector, and options that
+// work on the single content string of a response, which combine "pages"
+// leaves empty.
+func checkPaginate(req *models.ScrapeRequest) error {
+	if req.Paginate == nil {
+		return nil
+	}
+	if req.Paginate.NextSelector != "" {
+		if _, err := selector.Parse(req.Paginate.NextSelector); err != nil {
+			return models.NewScrapeError(models.ErrCodeInvalidInput, "paginate.next_selector: "+err.Error(), err)
+		}
+	}
+	if req.Paginate.Combine != "pages" {
+		return nil
+	}
+	var opt string
+	switch {
+	case req.MaxTokens > 0:
+		opt = "max_tokens"
+	case req.RemoveBoilerplate:
+		opt = "remove_boilerplate"
+	case req.Chunking != nil:
+		opt = "chunking"
+	default:
+		return nil
+	}
+	return models.NewScrapeError(
+		models.ErrCodeInvalidInput,
+		fmt.Sprintf("%s is not supported with paginate.combine \"pages\"", opt),
+		nil,
+	)
+}
+
+// stitchPages cleans the additional pages of a paginated scrape and merges
+// them into resp. resp already holds the cleaned first page.
+//
+// With combine "concat", the page contents are joined into resp.Content with
+// format-appropriate separators. With combine "pages", every page (including
+// the first) is moved into resp.Pages and resp.Content is cleared. Token
+// estimates are summed across pages in both modes.
+func stitchPages(cl *cleaner.Cleaner, req *models.ScrapeRequest, result *scraper.ScrapeResult, resp *models.ScrapeResponse, cleanOpts []cleaner.CleanOptions) {
+	pages := []models.PageContent{{
+		URL:     result.FinalURL,
+		Content: resp.Content,
+		Tokens:  resp.Tokens,
+	}}
+
+	for _, page := range result.Pages {
+		pageResp, err := cl.Clean(page.RawHTML, page.FinalURL, req.OutputFormat, req.ExtractMode, cleanOpts...)
+		if err != nil {
+			slog.Warn("paginate: failed to clean page, skipping",
+				"url", page.FinalURL, "error", err)
+			continue
+		}
+		pages = append(pages, models.PageContent{
+			URL:     page.FinalURL,
+			Content: pageResp.Content,
+			Tokens:  pageResp.Tokens,
+		})
+	}
+
+	var original, cleaned int
+	for _, page := range pages {
+		original += page.Tokens.OriginalEstimate
+		cleaned += page.Tokens.CleanedEstimate
+	}
+	resp.Tokens.OriginalEstimate = original
+	resp.Tokens.CleanedEstimate = cleaned
+	resp.Tokens.SavingsPercent = 0
+	if original > 0 {
+		savings := float64(original-cleaned) / float64(original) * 100
+		resp.Tokens.SavingsPercent = math.Round(savings*100) / 100
+	}
+
+	if req.Paginate.Combine == "pages" {
+		resp.Pages = pages
+		resp.Content = ""
+		return
+	}
+
+	var buf strings.Builder
+	for i, page := range pages {
+		if i > 0 {
+			buf.WriteString(pageSeparator(req.OutputFormat, i+1, page.URL))
+		}
+		buf.WriteString(page.Content)
+	}
+	resp.Content = buf.String()
+}
+
+// pageSeparator returns the marker placed between concatenated pages.
+func pageSeparator(format string, pageNum int, pageURL string) string {
+	switch format {
+	case "html":
+		return fmt.Sprintf("\n<hr data-purify-page=\"%d\" data-url=\"%s\">\n", pageNum, html.EscapeString(pageURL))
+	case "text":
+		return fmt.Sprintf("\n\n----- page %d: %s -----\n\n", pageNum, pageURL)
+	default:
+		return fmt.Sprintf("\n\n---\n\n<!-- page %d: %s -->\n\n", pageNum, pageURL)
+	}
+}
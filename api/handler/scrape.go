@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"time"
 
@@ -39,6 +40,20 @@ func Scrape(sc *scraper.Scraper, cl *cleaner.Cleaner, cc *cache.Cache) gin.Handl
 		}
 		req.Defaults()
 
+		// ── 1a. Expand a DevTools Recorder script into actions ──────
+		if err := expandRecording(&req); err != nil {
+			respondError(c, err, models.TimingInfo{
+				TotalMs: time.Since(totalStart).Milliseconds(),
+			})
+			return
+		}
+		if err := checkPaginate(&req); err != nil {
+			respondError(c, err, models.TimingInfo{
+				TotalMs: time.Since(totalStart).Milliseconds(),
+			})
+			return
+		}
+
 		// SSE mode: stream progress events instead of JSON response.
 		if c.GetHeader("Accept") == "text/event-stream" {
 			handleScrapeSSE(c, sc, cl, cc, &req)
@@ -46,8 +61,8 @@ func Scrape(sc *scraper.Scraper, cl *cleaner.Cleaner, cc *cache.Cache) gin.Handl
 		}
 
 		// ── 1b. Cache lookup ───────────────────────────────────────
-		if cc != nil && req.MaxAge > 0 {
-			cacheKey := cache.Key(req.URL, req.OutputFormat, req.ExtractMode)
+		if useCache(cc, &req) {
+			cacheKey := scrapeCacheKey(&req)
 			if cached, hit := cc.Get(cacheKey, req.MaxAge); hit {
 				cached.CacheStatus = "hit"
 				cached.Timing = models.TimingInfo{
@@ -73,14 +88,7 @@ func Scrape(sc *scraper.Scraper, cl *cleaner.Cleaner, cc *cache.Cache) gin.Handl
 
 		// ── 3. Clean ────────────────────────────────────────────────
 		cleanStart := time.Now()
-		var cleanOpts []cleaner.CleanOptions
-		if len(req.IncludeTags) > 0 || len(req.ExcludeTags) > 0 || req.CSSSelector != "" {
-			cleanOpts = append(cleanOpts, cleaner.CleanOptions{
-				IncludeTags: req.IncludeTags,
-				ExcludeTags: req.ExcludeTags,
-				CSSSelector: req.CSSSelector,
-			})
-		}
+		cleanOpts := cleanOptions(&req)
 		resp, err := cl.Clean(result.RawHTML, req.URL, req.OutputFormat, req.ExtractMode, cleanOpts...)
 		cleaningMs := time.Since(cleanStart).Milliseconds()
 
@@ -93,6 +101,14 @@ func Scrape(sc *scraper.Scraper, cl *cleaner.Cleaner, cc *cache.Cache) gin.Handl
 			return
 		}
 
+		// ── 3b. Stitch paginated pages ──────────────────────────────
+		if req.Paginate != nil {
+			stitchPages(cl, &req, result, resp, cleanOpts)
+		}
+		if req.Downloads != nil {
+			attachDownloads(cl, &req, result, resp)
+		}
+
 		// ── 4. Title fallback ───────────────────────────────────────
 		if resp.Metadata.Title == "" {
 			resp.Metadata.Title = result.Title
@@ -103,6 +119,19 @@ func Scrape(sc *scraper.Scraper, cl *cleaner.Cleaner, cc *cache.Cache) gin.Handl
 		resp.StatusCode = result.StatusCode
 		resp.FinalURL = result.FinalURL
 		resp.EngineUsed = result.EngineUsed
+		resp.InteractiveElements = result.InteractiveElements
+		resp.Debug = debugInfo(result)
+		if req.OutputFormat == "accessibility" {
+			useAccessibility(resp, result)
+		}
+		if req.RemoveBoilerplate && req.OutputFormat != "accessibility" {
+			stripBoilerplate(resp, learnedBoilerplate(pageHost(resp, req.URL)), req.OutputFormat, nil, "")
+		}
+		applyMaxTokens(resp, req.MaxTokens, req.Tokenizer)
+		countTokens(resp, result, req.Tokenizer)
+		if req.Chunking != nil {
+			resp.Chunks = cleaner.Chunk(resp.Content, *req.Chunking, req.Tokenizer)
+		}
 		resp.Timing = models.TimingInfo{
 			TotalMs:      time.Since(totalStart).Milliseconds(),
 			NavigationMs: navigationMs,
@@ -110,8 +139,8 @@ func Scrape(sc *scraper.Scraper, cl *cleaner.Cleaner, cc *cache.Cache) gin.Handl
 		}
 
 		// ── 6. Cache store ──────────────────────────────────────────
-		if cc != nil && req.MaxAge > 0 {
-			cacheKey := cache.Key(req.URL, req.OutputFormat, req.ExtractMode)
+		if useCache(cc, &req) {
+			cacheKey := scrapeCacheKey(&req)
 			cc.Set(cacheKey, resp)
 			resp.CacheStatus = "miss"
 		}
@@ -120,6 +149,139 @@ func Scrape(sc *scraper.Scraper, cl *cleaner.Cleaner, cc *cache.Cache) gin.Handl
 	}
 }
 
+// useCache reports whether the response cache applies to req. Requests
+// with downloads are never cached: their base64 files would bloat it.
+func useCache(cc *cache.Cache, req *models.ScrapeRequest) bool {
+	return cc != nil && req.MaxAge > 0 && req.Downloads == nil
+}
+
+// scrapeCacheKey keys the response cache on every request option that can
+// change the response (pagination, filtering, truncation, chunking, ...),
+// not only the URL, format and extract mode.
+func scrapeCacheKey(req *models.ScrapeRequest) string {
+	opts := *req
+	opts.MaxAge = 0
+	b, _ := json.Marshal(&opts)
+	return cache.Key(req.URL, req.OutputFormat, req.ExtractMode, string(b))
+}
+
+// cleanOptions collects the request's content-filtering options for the
+// cleaner, or returns nil when none are set.
+func cleanOptions(req *models.ScrapeRequest) []cleaner.CleanOptions {
+	if len(req.IncludeTags) == 0 && len(req.ExcludeTags) == 0 && req.CSSSelector == "" &&
+		!req.BlockAds && !req.RemoveHidden && req.Query == "" {
+		return nil
+	}
+	return []cleaner.CleanOptions{{
+		IncludeTags:    req.IncludeTags,
+		ExcludeTags:    req.ExcludeTags,
+		CSSSelector:    req.CSSSelector,
+		BlockAds:       req.BlockAds,
+		RemoveHidden:   req.RemoveHidden,
+		Query:          req.Query,
+		QueryNeighbors: req.QueryNeighbors,
+	}}
+}
+
+// debugInfo builds the response debug section from a scrape result, or
+// returns nil when there is nothing to report.
+func debugInfo(result *scraper.ScrapeResult) *models.DebugInfo {
+	if result.BlockedRequests == 0 && result.ConsentManager == "" {
+		return nil
+	}
+	return &models.DebugInfo{
+		BlockedRequests: result.BlockedRequests,
+		ConsentManager:  result.ConsentManager,
+	}
+}
+
+// useAccessibility replaces the cleaned content with the page's accessibility
+// tree and recomputes the token savings against it.
+func useAccessibility(resp *models.ScrapeResponse, result *scraper.ScrapeResult) {
+	resp.Content = result.Accessibility
+	updateEstimate(&resp.Tokens, resp.Content)
+}
+
+// applyMaxTokens trims the content to maxTokens (see cleaner.Truncate).
+// A no-op when maxTokens is 0 or the content fits.
+func applyMaxTokens(resp *models.ScrapeResponse, maxTokens int, tokenizer string) {
+	if maxTokens <= 0 {
+		return
+	}
+	content, removed := cleaner.Truncate(resp.Content, maxTokens, tokenizer)
+	if removed == 0 {
+		return
+	}
+	resp.Content = content
+	resp.Truncated = true
+	resp.TruncatedTokens = removed
+	updateEstimate(&resp.Tokens, content)
+}
+
+// updateEstimate recomputes the cleaned estimate and savings after the
+// content was replaced.
+func updateEstimate(tokens *models.TokenInfo, content string) {
+	tokens.CleanedEstimate = cleaner.EstimateTokens(content)
+	tokens.SavingsPercent = 0
+	if orig := tokens.OriginalEstimate; orig > 0 {
+		savings := float64(orig-tokens.CleanedEstimate) / float64(orig) * 100
+		tokens.SavingsPercent = math.Round(savings*100) / 100
+	}
+}
+
+// countTokens adds exact token counts from the chosen BPE tokenizer next to
+// the estimates, and bases the savings on them. The original count covers
+// every fetched page of a paginated scrape, and the cleaned count every
+// entry of resp.Pages when combine "pages" left Content empty. A no-op for
+// the estimate.
+func countTokens(resp *models.ScrapeResponse, result *scraper.ScrapeResult, tokenizer string) {
+	if tokenizer == "" || tokenizer == cleaner.TokenizerEstimate {
+		return
+	}
+	original := cleaner.CountTokens(result.RawHTML, tokenizer)
+	for _, page := range result.Pages {
+		original += cleaner.CountTokens(page.RawHTML, tokenizer)
+	}
+	cleaned := cleaner.CountTokens(resp.Content, tokenizer)
+	for _, page := range resp.Pages {
+		cleaned += cleaner.CountTokens(page.Content, tokenizer)
+	}
+
+	resp.Tokens.Tokenizer = tokenizer
+	resp.Tokens.OriginalCount = original
+	resp.Tokens.CleanedCount = cleaned
+	resp.Tokens.SavingsPercent = 0
+	if original > 0 {
+		savings := float64(original-cleaned) / float64(original) * 100
+		resp.Tokens.SavingsPercent = math.Round(savings*100) / 100
+	}
+}
+
+// expandRecording translates req.ActionsRecording into actions that run
+// before any explicit req.Actions, enforcing the same 50-action limit.
+func expandRecording(req *models.ScrapeRequest) error {
+	if len(req.ActionsRecording) == 0 {
+		return nil
+	}
+	recorded, err := scraper.ParseRecording(req.ActionsRecording, req.URL)
+	if err != nil {
+		return err
+	}
+	if total := len(recorded) + len(req.Actions); total > maxActions {
+		return models.NewScrapeError(
+			models.ErrCodeInvalidInput,
+			fmt.Sprintf("actions_recording: %d actions after translation (max %d)", total, maxActions),
+			nil,
+		)
+	}
+	req.Actions = append(recorded, req.Actions...)
+	req.ActionsRecording = nil
+	return nil
+}
+
+// maxActions mirrors the max=50 binding on ScrapeRequest.Actions.
+const maxActions = 50
+
 // respondError maps a ScrapeError to the correct HTTP status code and writes
 // a structured JSON error response.
 func respondError(c *gin.Context, err error, timing models.TimingInfo) {
@@ -168,8 +330,8 @@ func handleScrapeSSE(c *gin.Context, sc *scraper.Scraper, cl *cleaner.Cleaner, c
 	})
 
 	// 2. Cache lookup.
-	if cc != nil && req.MaxAge > 0 {
-		cacheKey := cache.Key(req.URL, req.OutputFormat, req.ExtractMode)
+	if useCache(cc, req) {
+		cacheKey := scrapeCacheKey(req)
 		if cached, hit := cc.Get(cacheKey, req.MaxAge); hit {
 			cached.CacheStatus = "hit"
 			cached.Timing = models.TimingInfo{
@@ -202,14 +364,7 @@ func handleScrapeSSE(c *gin.Context, sc *scraper.Scraper, cl *cleaner.Cleaner, c
 
 	// 5. Clean.
 	cleanStart := time.Now()
-	var cleanOpts []cleaner.CleanOptions
-	if len(req.IncludeTags) > 0 || len(req.ExcludeTags) > 0 || req.CSSSelector != "" {
-		cleanOpts = append(cleanOpts, cleaner.CleanOptions{
-			IncludeTags: req.IncludeTags,
-			ExcludeTags: req.ExcludeTags,
-			CSSSelector: req.CSSSelector,
-		})
-	}
+	cleanOpts := cleanOptions(req)
 	resp, err := cl.Clean(result.RawHTML, req.URL, req.OutputFormat, req.ExtractMode, cleanOpts...)
 	cleaningMs := time.Since(cleanStart).Milliseconds()
 
@@ -220,6 +375,13 @@ func handleScrapeSSE(c *gin.Context, sc *scraper.Scraper, cl *cleaner.Cleaner, c
 		return
 	}
 
+	if req.Paginate != nil {
+		stitchPages(cl, req, result, resp, cleanOpts)
+	}
+	if req.Downloads != nil {
+		attachDownloads(cl, req, result, resp)
+	}
+
 	// 6. Title fallback + fill fields.
 	if resp.Metadata.Title == "" {
 		resp.Metadata.Title = result.Title
@@ -228,6 +390,19 @@ func handleScrapeSSE(c *gin.Context, sc *scraper.Scraper, cl *cleaner.Cleaner, c
 	resp.StatusCode = result.StatusCode
 	resp.FinalURL = result.FinalURL
 	resp.EngineUsed = result.EngineUsed
+	resp.InteractiveElements = result.InteractiveElements
+	resp.Debug = debugInfo(result)
+	if req.OutputFormat == "accessibility" {
+		useAccessibility(resp, result)
+	}
+	if req.RemoveBoilerplate && req.OutputFormat != "accessibility" {
+		stripBoilerplate(resp, learnedBoilerplate(pageHost(resp, req.URL)), req.OutputFormat, nil, "")
+	}
+	applyMaxTokens(resp, req.MaxTokens, req.Tokenizer)
+	countTokens(resp, result, req.Tokenizer)
+	if req.Chunking != nil {
+		resp.Chunks = cleaner.Chunk(resp.Content, *req.Chunking, req.Tokenizer)
+	}
 	resp.Timing = models.TimingInfo{
 		TotalMs:      time.Since(totalStart).Milliseconds(),
 		NavigationMs: navigationMs,
@@ -235,8 +410,8 @@ func handleScrapeSSE(c *gin.Context, sc *scraper.Scraper, cl *cleaner.Cleaner, c
 	}
 
 	// 7. Cache store.
-	if cc != nil && req.MaxAge > 0 {
-		cacheKey := cache.Key(req.URL, req.OutputFormat, req.ExtractMode)
+	if useCache(cc, req) {
+		cacheKey := scrapeCacheKey(req)
 		cc.Set(cacheKey, resp)
 		resp.CacheStatus = "miss"
 	}
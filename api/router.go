@@ -10,6 +10,7 @@ import (
 	"github.com/use-agent/purify/cleaner"
 	"github.com/use-agent/purify/config"
 	"github.com/use-agent/purify/llm"
+	"github.com/use-agent/purify/monitor"
 	"github.com/use-agent/purify/scraper"
 )
 
@@ -21,7 +22,7 @@ import (
 //	API:     Auth (if enabled) → RateLimit
 //
 // Health endpoint is intentionally outside auth so monitoring probes always work.
-func NewRouter(sc *scraper.Scraper, cl *cleaner.Cleaner, llmClient *llm.Client, cfg *config.Config, cc *cache.Cache, startTime time.Time) *gin.Engine {
+func NewRouter(sc *scraper.Scraper, cl *cleaner.Cleaner, llmClient *llm.Client, cfg *config.Config, cc *cache.Cache, mon *monitor.Scheduler, startTime time.Time) *gin.Engine {
 	gin.SetMode(cfg.Server.Mode)
 
 	r := gin.New()
@@ -57,5 +58,14 @@ func NewRouter(sc *scraper.Scraper, cl *cleaner.Cleaner, llmClient *llm.Client,
 	// Map
 	protected.POST("/map", handler.PostMap(sc, cl))
 
+	// Browse (interactive session over WebSocket)
+	protected.GET("/browse", handler.Browse(sc, cl, cfg.Browse))
+
+	// Monitors (scheduled re-scrapes with change webhooks)
+	protected.POST("/monitors", handler.PostMonitor(mon, cfg.Monitor))
+	protected.GET("/monitors", handler.ListMonitors(mon))
+	protected.GET("/monitors/:id", handler.GetMonitor(mon))
+	protected.DELETE("/monitors/:id", handler.DeleteMonitor(mon))
+
 	return r
 }
@@ -37,13 +37,17 @@ func New(maxEntries int) *Cache {
 }
 
 // Key generates a cache key from the URL, output format, and extract mode.
-func Key(url, outputFormat, extractMode string) string {
+// options is a canonical encoding of the other request options that change
+// the response.
+func Key(url, outputFormat, extractMode, options string) string {
 	h := sha256.New()
 	h.Write([]byte(url))
 	h.Write([]byte("|"))
 	h.Write([]byte(outputFormat))
 	h.Write([]byte("|"))
 	h.Write([]byte(extractMode))
+	h.Write([]byte("|"))
+	h.Write([]byte(options))
 	return hex.EncodeToString(h.Sum(nil))
 }
 
@@ -0,0 +1,202 @@
+package cleaner
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/use-agent/purify/simhash"
+)
+
+// minBoilerplatePages is how many pages of a site must be seen before any
+// block is treated as boilerplate.
+const minBoilerplatePages = 3
+
+// boilerplateDistance is the Hamming distance within which a block matches
+// a learned template, so small variations (dates, counters) still match.
+const boilerplateDistance = 3
+
+// Boilerplate is the set of block fingerprints learned as a site's
+// template: header, footer, cookie and sidebar text repeated on most pages.
+type Boilerplate []uint64
+
+// BoilerplateLearner counts on how many pages of one site each content
+// block appears. Blocks within boilerplateDistance of each other count as
+// the same block, so a footer carrying a date or counter is still learned.
+// Safe for concurrent use.
+type BoilerplateLearner struct {
+	mu    sync.Mutex
+	pages int
+
+	// clusters indexes each cluster's first fingerprint by cluster
+	// number; count and members are indexed by cluster number too.
+	clusters *simhash.Index
+	count    []int      // pages the cluster appeared on
+	members  [][]uint64 // distinct fingerprints seen in the cluster
+}
+
+// NewBoilerplateLearner returns an empty learner.
+func NewBoilerplateLearner() *BoilerplateLearner {
+	return &BoilerplateLearner{clusters: simhash.NewIndex(boilerplateDistance)}
+}
+
+// Observe records the blocks of one page's cleaned content in the given
+// output format.
+func (l *BoilerplateLearner) Observe(content, format string) {
+	blocks := boilerplateBlocks(content, format)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pages++
+	page := make(map[int]bool)
+	for _, b := range blocks {
+		c := l.cluster(b.fp)
+		if !page[c] {
+			page[c] = true
+			l.count[c]++
+		}
+	}
+}
+
+// cluster returns the cluster fp belongs to, starting a new one when no
+// cluster is within boilerplateDistance.
+func (l *BoilerplateLearner) cluster(fp uint64) int {
+	if m, ok := l.clusters.Nearest(fp); ok {
+		c, _ := strconv.Atoi(m.ID)
+		if !slices.Contains(l.members[c], fp) {
+			l.members[c] = append(l.members[c], fp)
+		}
+		return c
+	}
+	c := len(l.count)
+	l.clusters.Add(strconv.Itoa(c), fp)
+	l.count = append(l.count, 0)
+	l.members = append(l.members, []uint64{fp})
+	return c
+}
+
+// Templates returns the fingerprints of the blocks seen on more than
+// percent% of the observed pages, or nil until minBoilerplatePages pages
+// were observed.
+func (l *BoilerplateLearner) Templates(percent int) Boilerplate {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pages < minBoilerplatePages {
+		return nil
+	}
+	var tpl Boilerplate
+	for c, n := range l.count {
+		if n*100 > percent*l.pages {
+			tpl = append(tpl, l.members[c]...)
+		}
+	}
+	return tpl
+}
+
+// Strip removes the blocks of content matching the templates and returns
+// the content with the number of blocks removed. Headings are never
+// removed.
+func (tpl Boilerplate) Strip(content, format string) (string, int) {
+	if len(tpl) == 0 {
+		return content, 0
+	}
+	if format == "html" {
+		return tpl.stripHTML(content)
+	}
+
+	var out strings.Builder
+	removed, last := 0, 0
+	for _, b := range boilerplateBlocks(content, format) {
+		if !tpl.matches(b.fp) {
+			continue
+		}
+		out.WriteString(strings.TrimRight(content[last:b.start], "\n"))
+		last = b.end
+		removed++
+	}
+	if removed == 0 {
+		return content, 0
+	}
+	out.WriteString(content[last:])
+	return strings.TrimSpace(out.String()), removed
+}
+
+// stripHTML removes matching blocks from cleaned HTML.
+func (tpl Boilerplate) stripHTML(content string) (string, int) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return content, 0
+	}
+	var blocks []queryBlock
+	collectQueryBlocks(doc.Find("body"), &blocks)
+	removed := 0
+	for _, b := range blocks {
+		if fp := blockFingerprint(b.sel.Text()); !b.heading && fp != 0 && tpl.matches(fp) {
+			b.sel.Remove()
+			removed++
+		}
+	}
+	if removed == 0 {
+		return content, 0
+	}
+	out, err := doc.Find("body").Html()
+	if err != nil {
+		return content, 0
+	}
+	return strings.TrimSpace(out), removed
+}
+
+func (tpl Boilerplate) matches(fp uint64) bool {
+	for _, t := range tpl {
+		if simhash.Similar(fp, t, boilerplateDistance) {
+			return true
+		}
+	}
+	return false
+}
+
+// boilerplateBlock is a fingerprinted non-heading block of content.
+type boilerplateBlock struct {
+	start, end int
+	fp         uint64
+}
+
+// boilerplateBlocks fingerprints the non-heading blocks of content. HTML
+// content is parsed into block elements; other formats are split like
+// markdown, with link targets ignored.
+func boilerplateBlocks(content, format string) []boilerplateBlock {
+	var out []boilerplateBlock
+	if format == "html" {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+		if err != nil {
+			return nil
+		}
+		var blocks []queryBlock
+		collectQueryBlocks(doc.Find("body"), &blocks)
+		for _, b := range blocks {
+			if fp := blockFingerprint(b.sel.Text()); !b.heading && fp != 0 {
+				out = append(out, boilerplateBlock{fp: fp})
+			}
+		}
+		return out
+	}
+
+	blocks, _ := markdownBlocks(content)
+	for _, b := range blocks {
+		if b.heading {
+			continue
+		}
+		text := mdLink.ReplaceAllString(content[b.start:b.end], "$1")
+		if fp := blockFingerprint(text); fp != 0 {
+			out = append(out, boilerplateBlock{start: b.start, end: b.end, fp: fp})
+		}
+	}
+	return out
+}
+
+// blockFingerprint is the SimHash of a block's text, ignoring case and
+// punctuation.
+func blockFingerprint(text string) uint64 {
+	return simhash.FingerprintWith(text, simhash.Options{})
+}
@@ -0,0 +1,456 @@
+package cleaner
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/use-agent/purify/models"
+)
+
+// Chunking defaults.
+const (
+	defaultChunkStrategy = "recursive"
+	defaultChunkTokens   = 512
+)
+
+// atxHeading matches a markdown ATX heading line ("## Title").
+var atxHeading = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.+?)[ \t#]*$`)
+
+// span is a byte range of the content being chunked.
+type span struct {
+	start, end int
+	heading    bool
+}
+
+// heading is a markdown heading found in the content.
+type heading struct {
+	offset int
+	level  int
+	text   string
+}
+
+// Chunk splits cleaned content (normally the markdown produced by Clean)
+// into retrieval-sized chunks, measured with the named tokenizer (see
+// CountTokens).
+//
+// Strategies:
+//
+//   - "heading": one chunk per section; sections over the target are split
+//     as in "recursive", but chunks never cross a heading.
+//   - "token": fixed windows of about TargetTokens, cut between words.
+//   - "sentence": whole sentences packed up to the target.
+//   - "recursive" (default): paragraphs and blocks packed up to the target;
+//     oversized blocks are split by lines, then sentences, then words.
+//
+// Every chunk's text is content[start:end] (in characters), so chunks with
+// overlap share a tail of the previous chunk. The heading and recursive
+// strategies keep fenced code blocks whole unless they exceed the target on
+// their own.
+func Chunk(content string, opts models.ChunkingOptions, tokenizer string) []models.Chunk {
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = defaultChunkStrategy
+	}
+	target := opts.TargetTokens
+	if target <= 0 {
+		target = defaultChunkTokens
+	}
+	overlap := opts.OverlapTokens
+	if overlap > target/2 {
+		overlap = target / 2
+	}
+
+	blocks, headings := markdownBlocks(content)
+	if len(blocks) == 0 {
+		return nil
+	}
+	c := &chunker{content: content, target: target, overlap: overlap, tokenizer: tokenizer}
+
+	var spans []span
+	switch strategy {
+	case "token":
+		var units []span
+		for _, w := range splitWords(content, span{start: 0, end: len(content)}) {
+			units = append(units, c.refine(w, levelRunes)...)
+		}
+		spans = c.pack(units)
+	case "sentence":
+		var units []span
+		for _, b := range blocks {
+			if b.heading {
+				units = append(units, b)
+				continue
+			}
+			for _, s := range splitSentences(content, b) {
+				units = append(units, c.refine(s, levelWords)...)
+			}
+		}
+		spans = c.pack(units)
+	case "heading":
+		for _, section := range sections(blocks) {
+			var units []span
+			for _, b := range section {
+				units = append(units, c.refine(b, levelLines)...)
+			}
+			spans = append(spans, c.pack(units)...)
+		}
+	default:
+		var units []span
+		for _, b := range blocks {
+			units = append(units, c.refine(b, levelLines)...)
+		}
+		spans = c.pack(units)
+	}
+
+	chunks := make([]models.Chunk, 0, len(spans))
+	runes := runeOffsets{s: content}
+	for i, s := range spans {
+		text := content[s.start:s.end]
+		chunks = append(chunks, models.Chunk{
+			Index:       i,
+			Text:        text,
+			HeadingPath: headingPath(headings, s.start),
+			Start:       runes.at(s.start),
+			End:         runes.at(s.end),
+			Tokens:      CountTokens(text, tokenizer),
+		})
+	}
+	return chunks
+}
+
+// markdownBlocks splits content into blocks separated by blank lines, with
+// each heading line as its own block and fenced code kept whole.
+func markdownBlocks(content string) ([]span, []heading) {
+	var blocks []span
+	var headings []heading
+	blockStart := -1
+	inFence := false
+	fence := ""
+
+	flush := func(end int) {
+		if blockStart >= 0 {
+			blocks = append(blocks, trimSpan(content, span{start: blockStart, end: end}))
+			blockStart = -1
+		}
+	}
+
+	for pos := 0; pos < len(content); {
+		lineEnd := strings.IndexByte(content[pos:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(content)
+		} else {
+			lineEnd += pos
+		}
+		line := content[pos:lineEnd]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case inFence:
+			if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			if blockStart < 0 {
+				blockStart = pos
+			}
+			inFence, fence = true, trimmed[:3]
+		case trimmed == "":
+			flush(pos)
+		default:
+			if m := atxHeading.FindStringSubmatch(line); m != nil {
+				flush(pos)
+				headings = append(headings, heading{offset: pos, level: len(m[1]), text: strings.TrimSpace(m[2])})
+				b := trimSpan(content, span{start: pos, end: lineEnd})
+				b.heading = true
+				blocks = append(blocks, b)
+			} else if blockStart < 0 {
+				blockStart = pos
+			}
+		}
+		pos = lineEnd + 1
+	}
+	flush(len(content))
+
+	// Drop blocks that trimmed to nothing.
+	out := blocks[:0]
+	for _, b := range blocks {
+		if b.end > b.start {
+			out = append(out, b)
+		}
+	}
+	return out, headings
+}
+
+// sections groups blocks so that each group starts at a heading (the first
+// group may hold content before the first heading).
+func sections(blocks []span) [][]span {
+	var out [][]span
+	for _, b := range blocks {
+		if b.heading || len(out) == 0 {
+			out = append(out, nil)
+		}
+		out[len(out)-1] = append(out[len(out)-1], b)
+	}
+	return out
+}
+
+// headingPath returns the titles of the headings in effect at offset,
+// outermost first.
+func headingPath(headings []heading, offset int) []string {
+	var stack []heading
+	for _, h := range headings {
+		if h.offset > offset {
+			break
+		}
+		for len(stack) > 0 && stack[len(stack)-1].level >= h.level {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, h)
+	}
+	if len(stack) == 0 {
+		return nil
+	}
+	path := make([]string, len(stack))
+	for i, h := range stack {
+		path[i] = h.text
+	}
+	return path
+}
+
+// Refinement levels, from coarsest to finest.
+const (
+	levelLines = iota
+	levelSentences
+	levelWords
+	levelRunes
+)
+
+// chunker packs spans of content into chunks of about target tokens.
+type chunker struct {
+	content   string
+	target    int
+	overlap   int
+	tokenizer string
+}
+
+func (c *chunker) tokens(s span) int {
+	return CountTokens(c.content[s.start:s.end], c.tokenizer)
+}
+
+// refine splits s into units that fit the target, trying lines, then
+// sentences, then words, then fixed rune windows.
+func (c *chunker) refine(s span, level int) []span {
+	if c.tokens(s) <= c.target {
+		return []span{s}
+	}
+	var parts []span
+	switch level {
+	case levelLines:
+		parts = splitLines(c.content, s)
+	case levelSentences:
+		parts = splitSentences(c.content, s)
+	case levelWords:
+		parts = splitWords(c.content, s)
+	default:
+		return c.splitRunes(s)
+	}
+	if len(parts) <= 1 {
+		return c.refine(s, level+1)
+	}
+	var out []span
+	for _, p := range parts {
+		out = append(out, c.refine(p, level+1)...)
+	}
+	return out
+}
+
+// splitRunes cuts s into windows sized to the target by its token density.
+func (c *chunker) splitRunes(s span) []span {
+	text := c.content[s.start:s.end]
+	n := utf8.RuneCountInString(text)
+	per := n * c.target / c.tokens(s)
+	if per < 1 {
+		per = 1
+	}
+	var out []span
+	start, count := s.start, 0
+	for i := range text {
+		if count == per {
+			out = append(out, span{start: start, end: s.start + i})
+			start, count = s.start+i, 0
+		}
+		count++
+	}
+	return append(out, span{start: start, end: s.end})
+}
+
+// pack greedily joins consecutive units into chunks of at most target
+// tokens (a single oversized unit becomes its own chunk). A chunk does not
+// end on a heading when more content follows, and each chunk after the
+// first starts with up to overlap tokens of the previous one.
+func (c *chunker) pack(units []span) []span {
+	// tokensOf counts units[i:j] as they appear in the content, including
+	// the whitespace between them.
+	tokensOf := func(i, j int) int {
+		return c.tokens(span{start: units[i].start, end: units[j-1].end})
+	}
+
+	// Token counts are close to additive when each unit carries the
+	// whitespace before it, so prefix sums give a starting guess for each
+	// chunk's end that the exact counts then correct.
+	prefix := make([]int, len(units)+1)
+	for k, u := range units {
+		piece := u
+		if k > 0 {
+			piece.start = units[k-1].end
+		}
+		prefix[k+1] = prefix[k] + c.tokens(piece)
+	}
+
+	var out []span
+	for i := 0; i < len(units); {
+		first := c.tokens(units[i])
+		guess := sort.Search(len(units)-i, func(n int) bool {
+			return first+prefix[i+1+n]-prefix[i+1] > c.target
+		}) + i
+		j := c.fit(len(units), i, guess, func(j int) bool { return tokensOf(i, j) <= c.target })
+		for j-1 > i && j < len(units) && units[j-1].heading {
+			j--
+		}
+		out = append(out, span{start: units[i].start, end: units[j-1].end})
+		if j >= len(units) {
+			break
+		}
+
+		k := j
+		for k-1 > i && !units[k-1].heading && tokensOf(k-1, j) <= c.overlap {
+			k--
+		}
+		i = k
+	}
+	return out
+}
+
+// fit returns the largest j in (i, n] for which ok(j) holds, or i+1 when
+// none does, searching outward from guess. ok must be monotone (true up to
+// some j, false after).
+func (c *chunker) fit(n, i, guess int, ok func(j int) bool) int {
+	guess = max(i+1, min(guess, n))
+	var lo, hi int // ok(lo) holds or lo == i+1; hi == n+1 or ok(hi) fails
+	if ok(guess) {
+		lo, hi = guess, guess+1
+		for step := 1; hi <= n && ok(hi); step *= 2 {
+			lo, hi = hi, hi+step
+		}
+		hi = min(hi, n+1)
+	} else {
+		lo, hi = guess-1, guess
+		for step := 1; lo > i+1 && !ok(lo); step *= 2 {
+			lo, hi = lo-step, lo
+		}
+		lo = max(lo, i+1)
+	}
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		if ok(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// splitLines splits s at newlines.
+func splitLines(content string, s span) []span {
+	var out []span
+	start := s.start
+	for i := s.start; i < s.end; i++ {
+		if content[i] == '\n' {
+			out = appendTrimmed(out, content, span{start: start, end: i})
+			start = i + 1
+		}
+	}
+	return appendTrimmed(out, content, span{start: start, end: s.end})
+}
+
+// splitSentences splits s after sentence-ending punctuation followed by
+// whitespace, or after CJK full stops.
+func splitSentences(content string, s span) []span {
+	var out []span
+	start := s.start
+	text := content[s.start:s.end]
+	for i, r := range text {
+		end := s.start + i + utf8.RuneLen(r)
+		switch r {
+		case '。', '！', '？':
+			out = appendTrimmed(out, content, span{start: start, end: end})
+			start = end
+		case '.', '!', '?':
+			if next, _ := utf8.DecodeRuneInString(content[end:s.end]); end == s.end || unicode.IsSpace(next) {
+				out = appendTrimmed(out, content, span{start: start, end: end})
+				start = end
+			}
+		}
+	}
+	return appendTrimmed(out, content, span{start: start, end: s.end})
+}
+
+// splitWords splits s at whitespace.
+func splitWords(content string, s span) []span {
+	var out []span
+	start := -1
+	text := content[s.start:s.end]
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				out = append(out, span{start: start, end: s.start + i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = s.start + i
+		}
+	}
+	if start >= 0 {
+		out = append(out, span{start: start, end: s.end})
+	}
+	return out
+}
+
+func appendTrimmed(out []span, content string, s span) []span {
+	if s = trimSpan(content, s); s.end > s.start {
+		out = append(out, s)
+	}
+	return out
+}
+
+// trimSpan shrinks s to exclude surrounding whitespace.
+func trimSpan(content string, s span) span {
+	text := content[s.start:s.end]
+	left := len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace))
+	right := len(strings.TrimRightFunc(text, unicode.IsSpace))
+	if right < left {
+		return span{start: s.start, end: s.start}
+	}
+	return span{start: s.start + left, end: s.start + right, heading: s.heading}
+}
+
+// runeOffsets converts byte offsets to character offsets, counting from
+// the previous lookup (chunks are visited in order, overlap aside).
+type runeOffsets struct {
+	s             string
+	byteAt, runes int
+}
+
+func (r *runeOffsets) at(b int) int {
+	if b < r.byteAt {
+		r.runes -= utf8.RuneCountInString(r.s[b:r.byteAt])
+	} else {
+		r.runes += utf8.RuneCountInString(r.s[r.byteAt:b])
+	}
+	r.byteAt = b
+	return r.runes
+}
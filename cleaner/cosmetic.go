@@ -0,0 +1,42 @@
+package cleaner
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/use-agent/purify/adblock"
+)
+
+// SetFilterEngine installs the filter lists whose element-hiding rules are
+// applied when CleanOptions.BlockAds is set.
+func (c *Cleaner) SetFilterEngine(e *adblock.Engine) {
+	c.filters = e
+}
+
+// hideElements removes the elements hidden by the filter lists' cosmetic
+// rules for the page's host. HTML is returned unchanged when no filter
+// lists are loaded or nothing matches.
+func (c *Cleaner) hideElements(rawHTML, sourceURL string) string {
+	if c.filters == nil {
+		return rawHTML
+	}
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return rawHTML
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawHTML))
+	if err != nil {
+		return rawHTML
+	}
+	hidden := c.filters.HiddenElements(doc.Nodes[0], u.Hostname())
+	if len(hidden) == 0 {
+		return rawHTML
+	}
+	doc.FindNodes(hidden...).Remove()
+	out, err := doc.Html()
+	if err != nil {
+		return rawHTML
+	}
+	return out
+}
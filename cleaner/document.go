@@ -0,0 +1,74 @@
+package cleaner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// ErrUnsupportedDocument is returned by CleanDocument for file types it
+// cannot extract text from.
+var ErrUnsupportedDocument = errors.New("unsupported document type")
+
+// CleanDocument extracts readable content from a downloaded file.
+//
+//   - HTML runs through the regular Clean pipeline in the requested format.
+//   - Plain-text types (text/*, JSON, XML, CSV) are returned as-is.
+//   - PDF text is extracted page by page; layout and images are not kept.
+//
+// Other types return ErrUnsupportedDocument.
+func (c *Cleaner) CleanDocument(data []byte, mimeType, sourceURL, format string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType = strings.ToLower(mimeType)
+	}
+
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		resp, err := c.Clean(string(data), sourceURL, format, "readability")
+		if err != nil {
+			return "", err
+		}
+		return resp.Content, nil
+
+	case strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/json",
+		mediaType == "application/xml":
+		return string(data), nil
+
+	case mediaType == "application/pdf":
+		return extractPDFText(data)
+
+	default:
+		return "", ErrUnsupportedDocument
+	}
+}
+
+// extractPDFText returns the plain text of a PDF. The PDF parser panics on
+// some malformed files, so panics are converted to errors.
+func extractPDFText(data []byte) (text string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed PDF: %v", r)
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	plain, err := r.GetPlainText()
+	if err != nil {
+		return "", err
+	}
+	b, err := io.ReadAll(plain)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
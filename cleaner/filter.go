@@ -1,12 +1,17 @@
 package cleaner
 
 import (
+	"log/slog"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/use-agent/purify/selector"
+	"golang.org/x/net/html"
 )
 
-// FilterContent applies CSS-selector-based content filtering to raw HTML.
+// FilterContent applies selector-based content filtering to raw HTML.
+// Selectors may be plain CSS or use any engine supported by the selector
+// package; invalid selectors are logged and ignored.
 //
 // Processing order:
 //  1. Remove elements matching excludeTags (if any).
@@ -25,15 +30,17 @@ func FilterContent(html string, includeTags, excludeTags []string) string {
 	}
 
 	// Step 1: Remove excluded elements.
-	for _, selector := range excludeTags {
-		doc.Find(selector).Remove()
+	for _, sel := range excludeTags {
+		doc.FindNodes(queryNodes(doc, sel)...).Remove()
 	}
 
 	// Step 2: Keep only included elements.
 	if len(includeTags) > 0 {
-		// Build a combined selector: "article, main, .content"
-		combined := strings.Join(includeTags, ", ")
-		matches := doc.Find(combined)
+		// Union of all include selectors, in document order.
+		included := querySet(doc, includeTags)
+		matches := doc.Find("*").FilterFunction(func(_ int, s *goquery.Selection) bool {
+			return included[s.Get(0)]
+		})
 		if matches.Length() > 0 {
 			// Collect the outer HTML of all matching elements.
 			var buf strings.Builder
@@ -56,3 +63,25 @@ func FilterContent(html string, includeTags, excludeTags []string) string {
 	}
 	return result
 }
+
+// queryNodes evaluates sel against the document. Invalid selectors match
+// nothing.
+func queryNodes(doc *goquery.Document, sel string) []*html.Node {
+	parsed, err := selector.Parse(sel)
+	if err != nil {
+		slog.Debug("filter: ignoring invalid selector", "selector", sel, "error", err)
+		return nil
+	}
+	return parsed.QueryAll(doc.Get(0))
+}
+
+// querySet returns the union of the nodes matched by sels.
+func querySet(doc *goquery.Document, sels []string) map[*html.Node]bool {
+	set := make(map[*html.Node]bool)
+	for _, sel := range sels {
+		for _, n := range queryNodes(doc, sel) {
+			set[n] = true
+		}
+	}
+	return set
+}
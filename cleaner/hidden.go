@@ -0,0 +1,70 @@
+package cleaner
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// hiddenSelector matches elements hidden by markup alone: the browser's
+// data-purify-hidden marks, the hidden attribute (except "until-found",
+// whose content is searchable), ARIA-hidden subtrees and the common
+// screen-reader-only utility classes.
+const hiddenSelector = `[data-purify-hidden], [hidden]:not([hidden="until-found"]), [aria-hidden="true"], ` +
+	`.sr-only, .visually-hidden, .visuallyhidden, .screen-reader-text, .screen-reader-only`
+
+// RemoveHidden strips elements that are not rendered: elements marked by
+// the browser from computed layout (data-purify-hidden) and, for HTML
+// without layout information, heuristics on the hidden and aria-hidden
+// attributes, inline display:none / visibility:hidden styles and
+// screen-reader-only classes.
+//
+// aria-hidden is ignored on elements that hold the page's main content
+// (main, article or h1), because modal dialogs set it on the app root.
+func RemoveHidden(rawHTML string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawHTML))
+	if err != nil {
+		return rawHTML
+	}
+
+	hidden := doc.Find(hiddenSelector).FilterFunction(func(_ int, s *goquery.Selection) bool {
+		if s.Is("html, body") {
+			return false
+		}
+		if v, _ := s.Attr("aria-hidden"); v == "true" && !s.Is("[data-purify-hidden], [hidden]") {
+			return s.Find("main, article, h1").Length() == 0
+		}
+		return true
+	})
+	styled := doc.Find("[style]").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		style, _ := s.Attr("style")
+		return hiddenByStyle(style) && !s.Is("html, body")
+	})
+	if hidden.Length() == 0 && styled.Length() == 0 {
+		return rawHTML
+	}
+	hidden.Remove()
+	styled.Remove()
+
+	out, err := doc.Html()
+	if err != nil {
+		return rawHTML
+	}
+	return out
+}
+
+// hiddenByStyle reports whether an inline style hides the element.
+func hiddenByStyle(style string) bool {
+	for _, decl := range strings.Split(style, ";") {
+		prop, val, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		prop = strings.ToLower(strings.TrimSpace(prop))
+		val = strings.ToLower(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(val), "!important")))
+		if (prop == "display" && val == "none") || (prop == "visibility" && val == "hidden") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package cleaner
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// lazySrcAttrs hold the real image URL on lazy-loading sites, in order of
+// preference.
+var lazySrcAttrs = []string{
+	"data-src", "data-lazy-src", "data-original", "data-lazy", "data-url",
+	"data-hi-res-src", "data-full-src",
+}
+
+// lazySrcsetAttrs hold responsive candidates, in order of preference.
+var lazySrcsetAttrs = []string{"srcset", "data-srcset", "data-lazy-srcset"}
+
+// ResolveLazyImages rewrites lazy-loaded images so their src holds the real
+// image: the largest srcset candidate (including <picture> sources), else a
+// data-src style attribute, else the existing src unless it is a
+// placeholder. <noscript> image fallbacks replace the placeholder image next
+// to them, or are unwrapped when there is none.
+//
+// HTML without lazy-loading markup is returned unchanged.
+func ResolveLazyImages(rawHTML string) string {
+	if !strings.Contains(rawHTML, "data-") && !strings.Contains(rawHTML, "srcset") &&
+		!strings.Contains(rawHTML, "<noscript") {
+		return rawHTML
+	}
+	doc, err := html.Parse(strings.NewReader(rawHTML))
+	if err != nil {
+		return rawHTML
+	}
+
+	var noscripts, imgs []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Noscript:
+				noscripts = append(noscripts, n)
+			case atom.Img:
+				imgs = append(imgs, n)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	changed := false
+	for _, ns := range noscripts {
+		if expandNoscriptImage(ns) {
+			changed = true
+		}
+	}
+	for _, img := range imgs {
+		if img.Parent == nil {
+			continue // replaced by a <noscript> fallback
+		}
+		if resolveImage(img) {
+			changed = true
+		}
+	}
+	if !changed {
+		return rawHTML
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return rawHTML
+	}
+	return buf.String()
+}
+
+// expandNoscriptImage replaces a <noscript> holding an image with that
+// markup. A placeholder <img> right before the <noscript> is dropped, since
+// the fallback is the same image.
+func expandNoscriptImage(ns *html.Node) bool {
+	text := ns.FirstChild
+	if text == nil || text.Type != html.TextNode || !strings.Contains(text.Data, "<img") || ns.Parent == nil {
+		return false
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(text.Data), &html.Node{
+		Type: html.ElementNode, Data: "div", DataAtom: atom.Div,
+	})
+	if err != nil || len(nodes) == 0 {
+		return false
+	}
+
+	parent := ns.Parent
+	prev := ns.PrevSibling
+	for prev != nil && prev.Type == html.TextNode && strings.TrimSpace(prev.Data) == "" {
+		prev = prev.PrevSibling
+	}
+	if prev != nil && prev.Type == html.ElementNode && prev.DataAtom == atom.Img {
+		parent.RemoveChild(prev)
+	}
+	for _, n := range nodes {
+		parent.InsertBefore(n, ns)
+	}
+	parent.RemoveChild(ns)
+	return true
+}
+
+// resolveImage sets the real src of one <img>. Returns false when nothing
+// changed.
+func resolveImage(img *html.Node) bool {
+	src := attrValue(img, "src")
+
+	best, bestSize := "", 0.0
+	consider := func(n *html.Node) {
+		for _, key := range lazySrcsetAttrs {
+			if u, size := largestCandidate(attrValue(n, key)); u != "" && size > bestSize {
+				best, bestSize = u, size
+			}
+		}
+	}
+	consider(img)
+	if p := img.Parent; p != nil && p.DataAtom == atom.Picture {
+		for c := p.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.DataAtom == atom.Source {
+				consider(c)
+			}
+		}
+	}
+	if best == "" {
+		for _, key := range lazySrcAttrs {
+			if v := strings.TrimSpace(attrValue(img, key)); v != "" && !isPlaceholderSrc(v) {
+				best = v
+				break
+			}
+		}
+	}
+	if best == "" || best == src {
+		return false
+	}
+	if src != "" && !isPlaceholderSrc(src) && bestSize == 0 {
+		// A real src wins over data-src style attributes, which may be stale.
+		return false
+	}
+	setAttr(img, "src", best)
+	return true
+}
+
+// largestCandidate returns the URL of the largest candidate in a srcset and
+// its size: the width for "w" descriptors, the density for "x" descriptors
+// (a missing descriptor means 1x). Width descriptors win over densities.
+func largestCandidate(srcset string) (string, float64) {
+	best, bestSize := "", 0.0
+	for _, c := range parseSrcset(srcset) {
+		size := c.density
+		if c.width > 0 {
+			size = 1e6 + c.width
+		}
+		if !isPlaceholderSrc(c.url) && size > bestSize {
+			best, bestSize = c.url, size
+		}
+	}
+	return best, bestSize
+}
+
+// srcsetCandidate is one entry of a srcset attribute.
+type srcsetCandidate struct {
+	url     string
+	width   float64
+	density float64
+}
+
+// parseSrcset splits a srcset value into candidates. URLs are separated from
+// their descriptor by whitespace and candidates by commas; a URL may itself
+// contain commas.
+func parseSrcset(srcset string) []srcsetCandidate {
+	var out []srcsetCandidate
+	s := srcset
+	for {
+		s = strings.TrimLeft(s, " \t\n\r\f,")
+		if s == "" {
+			return out
+		}
+		end := strings.IndexAny(s, " \t\n\r\f")
+		if end < 0 {
+			end = len(s)
+		}
+		u := s[:end]
+		s = s[end:]
+
+		descriptor := ""
+		if strings.HasSuffix(u, ",") {
+			u = strings.TrimRight(u, ",")
+		} else if i := strings.IndexByte(s, ','); i >= 0 {
+			descriptor, s = s[:i], s[i+1:]
+		} else {
+			descriptor, s = s, ""
+		}
+
+		c := srcsetCandidate{url: u, density: 1}
+		d := strings.TrimSpace(descriptor)
+		if v, err := strconv.ParseFloat(strings.TrimSuffix(d, "w"), 64); err == nil && strings.HasSuffix(d, "w") {
+			c.width = v
+		} else if v, err := strconv.ParseFloat(strings.TrimSuffix(d, "x"), 64); err == nil && strings.HasSuffix(d, "x") {
+			c.density = v
+		}
+		out = append(out, c)
+	}
+}
+
+// isPlaceholderSrc reports whether an image URL is a lazy-loading
+// placeholder rather than the real image.
+func isPlaceholderSrc(src string) bool {
+	lower := strings.ToLower(strings.TrimSpace(src))
+	if lower == "" || lower == "#" || strings.HasPrefix(lower, "data:") || strings.HasPrefix(lower, "about:") {
+		return true
+	}
+	for _, marker := range []string{"placeholder", "spacer", "blank.gif", "pixel.gif", "transparent.gif", "1x1", "lazy.gif", "loading.gif", "grey.gif", "gray.gif"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// setAttr sets or adds an attribute on n.
+func setAttr(n *html.Node, key, val string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
@@ -10,6 +10,7 @@ import (
 	readability "github.com/go-shiori/go-readability"
 
 	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"github.com/use-agent/purify/adblock"
 	"github.com/use-agent/purify/models"
 )
 
@@ -21,6 +22,7 @@ import (
 // The converter is created once and reused across all requests (goroutine-safe).
 type Cleaner struct {
 	mdConverter *converter.Converter
+	filters     *adblock.Engine
 }
 
 // NewCleaner initialises the Cleaner with a pre-configured Markdown converter.
@@ -35,6 +37,18 @@ type CleanOptions struct {
 	IncludeTags []string
 	ExcludeTags []string
 	CSSSelector string
+
+	// BlockAds removes elements hidden by the cosmetic rules of the
+	// configured filter lists.
+	BlockAds bool
+
+	// RemoveHidden strips elements that are not rendered (see RemoveHidden).
+	RemoveHidden bool
+
+	// Query keeps only the extracted blocks relevant to it, plus
+	// QueryNeighbors blocks around each (see FilterByQuery).
+	Query          string
+	QueryNeighbors int
 }
 
 // Clean runs the full pipeline and returns a partial ScrapeResponse
@@ -42,27 +56,43 @@ type CleanOptions struct {
 //
 // Flow:
 //  1. Estimate original tokens from raw HTML.
-//  1b. Apply include/exclude tag filters (if provided).
-//  2. Stage 1: go-readability extracts main content.
+//  2. Expand declarative shadow roots into light DOM.
+//  3. Resolve lazy-loaded image URLs.
+//  4. Remove hidden elements, apply include/exclude tag filters and
+//     element hiding (if requested).
+//  5. Stage 1: go-readability extracts main content.
 //     Fallback: if extraction fails or content is too short, use raw HTML.
-//  3. Stage 2: convert to the requested output format.
-//  4. Estimate cleaned tokens and compute savings.
-//  5. Assemble and return the partial response.
+//  6. Keep only the blocks relevant to the query (if requested).
+//  7. Stage 2: convert to the requested output format.
+//  8. Estimate cleaned tokens and compute savings.
+//  9. Extract links, images and OG metadata from the raw HTML.
+//  10. Assemble and return the partial response.
 func (c *Cleaner) Clean(rawHTML string, sourceURL string, format string, extractMode string, opts ...CleanOptions) (*models.ScrapeResponse, error) {
 	// ── 1. Original token estimate ──────────────────────────────────
 	originalTokens := EstimateTokens(rawHTML)
 
-	// ── 1b. Content filtering (include/exclude tags + CSS selector) ──
+	// ── 2. Flatten declarative shadow DOM ───────────────────────────
+	rawHTML = ExpandShadowRoots(rawHTML)
+
+	// ── 3. Resolve lazy-loaded images ───────────────────────────────
+	rawHTML = ResolveLazyImages(rawHTML)
+
+	// ── 4. Content filtering (include/exclude tags + CSS selector) ───
 	if len(opts) > 0 {
 		o := opts[0]
 
-		// CSS selector filter (remote feature).
+		// Hidden elements (layout marks + markup heuristics).
+		if o.RemoveHidden {
+			rawHTML = RemoveHidden(rawHTML)
+		}
+
+		// Selector filter (CSS or a prefixed selector engine).
 		if o.CSSSelector != "" {
 			filtered, err := ApplyCSSSelector(rawHTML, o.CSSSelector)
 			if err != nil {
 				return nil, models.NewScrapeError(
 					models.ErrCodeInvalidInput,
-					"invalid CSS selector: "+err.Error(),
+					"invalid selector: "+err.Error(),
 					err,
 				)
 			}
@@ -71,9 +101,14 @@ func (c *Cleaner) Clean(rawHTML string, sourceURL string, format string, extract
 
 		// Include/exclude tag filter (Phase 2 feature).
 		rawHTML = FilterContent(rawHTML, o.IncludeTags, o.ExcludeTags)
+
+		// Filter-list element hiding.
+		if o.BlockAds {
+			rawHTML = c.hideElements(rawHTML, sourceURL)
+		}
 	}
 
-	// ── 2. Stage 1: Content extraction ──────────────────────────────
+	// ── 5. Stage 1: Content extraction ──────────────────────────────
 	var article readability.Article
 	switch extractMode {
 	case "raw":
@@ -112,7 +147,13 @@ func (c *Cleaner) Clean(rawHTML string, sourceURL string, format string, extract
 		article, _ = ExtractContent(rawHTML, sourceURL)
 	}
 
-	// ── 3. Stage 2: Format conversion ───────────────────────────────
+	// ── 6. Query-focused filtering ──────────────────────────────────
+	if len(opts) > 0 && opts[0].Query != "" {
+		article.Content = FilterByQuery(article.Content, opts[0].Query, opts[0].QueryNeighbors)
+		article.TextContent = stripTags(article.Content)
+	}
+
+	// ── 7. Stage 2: Format conversion ───────────────────────────────
 	var content string
 	var err error
 
@@ -154,7 +195,7 @@ func (c *Cleaner) Clean(rawHTML string, sourceURL string, format string, extract
 		}
 	}
 
-	// ── 4. Cleaned token estimate + savings ─────────────────────────
+	// ── 8. Cleaned token estimate + savings ─────────────────────────
 	cleanedTokens := EstimateTokens(content)
 
 	savingsPercent := 0.0
@@ -164,12 +205,12 @@ func (c *Cleaner) Clean(rawHTML string, sourceURL string, format string, extract
 		savingsPercent = math.Round(savingsPercent*100) / 100
 	}
 
-	// ── 5. Extract links, images, OG metadata from raw HTML ────────
+	// ── 9. Extract links, images, OG metadata from raw HTML ────────
 	links := ExtractLinks(rawHTML, sourceURL)
 	images := ExtractImages(rawHTML, sourceURL)
 	ogMeta := ExtractOGMetadata(rawHTML)
 
-	// ── 6. Assemble partial response ────────────────────────────────
+	// ── 10. Assemble partial response ───────────────────────────────
 	return &models.ScrapeResponse{
 		Success: true,
 		Content: content,
@@ -84,42 +84,53 @@ func scoreElement(el *goquery.Selection) float64 {
 		return 0
 	}
 
-	text := strings.TrimSpace(el.Text())
-	textLen := len(text)
-	totalLen := len(fullHTML)
+	// --- link_density input: anchor text length ---
+	linkTextLen := 0
+	el.Find("a").Each(func(_ int, a *goquery.Selection) {
+		linkTextLen += len(strings.TrimSpace(a.Text()))
+	})
+
+	return blockSignals{
+		textLen:       len(strings.TrimSpace(el.Text())),
+		totalLen:      len(fullHTML),
+		linkTextLen:   linkTextLen,
+		tagWeight:     tagWeight(el),
+		classIDWeight: classIDWeight(el),
+	}.score()
+}
+
+// blockSignals are the inputs of the pruning score for one block of
+// content, whether a DOM element or a section of cleaned markdown.
+type blockSignals struct {
+	textLen       int // visible text length
+	totalLen      int // length including markup
+	linkTextLen   int // text inside links
+	tagWeight     float64
+	classIDWeight float64
+}
 
+// score combines the signals with the pruning weights.
+func (s blockSignals) score() float64 {
 	// --- text_density: ratio of visible text to total element size ---
 	textDensity := 0.0
-	if totalLen > 0 {
-		textDensity = float64(textLen) / float64(totalLen)
+	if s.totalLen > 0 {
+		textDensity = float64(s.textLen) / float64(s.totalLen)
 	}
 
 	// --- link_density: ratio of anchor text to total text ---
-	linkTextLen := 0
-	el.Find("a").Each(func(_ int, a *goquery.Selection) {
-		linkTextLen += len(strings.TrimSpace(a.Text()))
-	})
 	linkDensity := 0.0
-	if textLen > 0 {
-		linkDensity = float64(linkTextLen) / float64(textLen)
+	if s.textLen > 0 {
+		linkDensity = float64(s.linkTextLen) / float64(s.textLen)
 	}
 
-	// --- tag_weight: semantic tag bonus/penalty ---
-	tagW := tagWeight(el)
-
-	// --- class_id_weight: class/id attribute bonus/penalty ---
-	classIDW := classIDWeight(el)
-
 	// --- text_length: log-scale bonus for longer text blocks ---
-	textLenScore := math.Log10(float64(textLen) + 1)
+	textLenScore := math.Log10(float64(s.textLen) + 1)
 
-	score := textDensity*wTextDensity +
+	return textDensity*wTextDensity +
 		linkDensity*wLinkDensity +
-		tagW*wTagWeight +
-		classIDW*wClassIDWeight +
+		s.tagWeight*wTagWeight +
+		s.classIDWeight*wClassIDWeight +
 		textLenScore*wTextLength
-
-	return score
 }
 
 // tagWeight returns a score bonus/penalty based on the element's tag name.
@@ -0,0 +1,264 @@
+package cleaner
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// queryKeepRatio is the fraction of the best block score a block needs to
+// be kept by FilterByQuery.
+const queryKeepRatio = 0.3
+
+// queryBlockTags are the elements FilterByQuery scores as whole blocks.
+var queryBlockTags = map[string]bool{
+	"p": true, "li": true, "pre": true, "table": true, "blockquote": true,
+	"dt": true, "dd": true, "figure": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// queryStopwords are English function words ignored in queries and blocks.
+var queryStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "can": true, "do": true, "does": true, "for": true,
+	"from": true, "how": true, "i": true, "in": true, "is": true, "it": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "this": true,
+	"to": true, "was": true, "what": true, "when": true, "where": true,
+	"which": true, "who": true, "why": true, "with": true, "you": true,
+}
+
+// queryBlock is one scored block of the extracted content.
+type queryBlock struct {
+	sel     *goquery.Selection
+	heading bool
+	terms   map[string]int
+	length  int
+}
+
+// FilterByQuery keeps only the blocks of the extracted content HTML that are
+// relevant to query: each block (paragraph, list item, code block, table,
+// heading...) is ranked with BM25 against the query, weighted by the pruning
+// density signals so link lists and boilerplate rank below prose. Blocks
+// scoring at least queryKeepRatio of the best one are kept together with
+// the heading they sit under and the given number of neighboring blocks on
+// each side.
+//
+// Returns contentHTML unchanged when nothing matches the query.
+func FilterByQuery(contentHTML, query string, neighbors int) string {
+	qterms := queryTerms(query)
+	if len(qterms) == 0 {
+		return contentHTML
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(contentHTML))
+	if err != nil {
+		return contentHTML
+	}
+
+	var blocks []queryBlock
+	collectQueryBlocks(doc.Find("body"), &blocks)
+	if len(blocks) == 0 {
+		return contentHTML
+	}
+
+	// Document frequencies and average length over all blocks.
+	df := make(map[string]int)
+	totalLen := 0
+	for _, b := range blocks {
+		for t := range b.terms {
+			df[t]++
+		}
+		totalLen += b.length
+	}
+	n := float64(len(blocks))
+	avgLen := math.Max(float64(totalLen)/n, 1)
+
+	scores := make([]float64, len(blocks))
+	best := 0.0
+	for i, b := range blocks {
+		bm := 0.0
+		for _, t := range qterms {
+			tf := float64(b.terms[t])
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-float64(df[t])+0.5)/(float64(df[t])+0.5))
+			bm += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(b.length)/avgLen))
+		}
+		if bm == 0 {
+			continue
+		}
+		// Density signals scale the relevance by 0.5–1.5.
+		quality := 1 / (1 + math.Exp(-scoreElement(b.sel)/4))
+		scores[i] = bm * (0.5 + quality)
+		best = math.Max(best, scores[i])
+	}
+	if best == 0 {
+		return contentHTML
+	}
+
+	keep := make([]bool, len(blocks))
+	for i := range blocks {
+		if scores[i] < best*queryKeepRatio {
+			continue
+		}
+		lo, hi := i-neighbors, i+neighbors
+		if blocks[i].heading && neighbors == 0 {
+			hi++ // a matching heading brings its first block
+		}
+		for j := max(lo, 0); j <= hi && j < len(blocks); j++ {
+			keep[j] = true
+		}
+		for j := i; j >= 0; j-- {
+			if blocks[j].heading {
+				keep[j] = true
+				break
+			}
+		}
+	}
+
+	// Kept list items are rewrapped in their list so they stay items.
+	var buf strings.Builder
+	var list *html.Node
+	for i, b := range blocks {
+		if !keep[i] {
+			continue
+		}
+		parent := b.sel.Get(0).Parent
+		if goquery.NodeName(b.sel) != "li" {
+			parent = nil
+		}
+		if parent != list {
+			if list != nil {
+				buf.WriteString("</" + list.Data + ">\n")
+			}
+			if parent != nil {
+				buf.WriteString("<" + parent.Data + ">\n")
+			}
+			list = parent
+		}
+		if h, err := goquery.OuterHtml(b.sel); err == nil {
+			buf.WriteString(h)
+			buf.WriteString("\n")
+		}
+	}
+	if list != nil {
+		buf.WriteString("</" + list.Data + ">\n")
+	}
+	return buf.String()
+}
+
+// collectQueryBlocks walks sel's element children in document order,
+// recording block elements and text-bearing leaves as queryBlocks.
+func collectQueryBlocks(sel *goquery.Selection, blocks *[]queryBlock) {
+	sel.Children().Each(func(_ int, el *goquery.Selection) {
+		tag := goquery.NodeName(el)
+		switch {
+		case tag == "script" || tag == "style" || tag == "noscript":
+			return
+		case queryBlockTags[tag]:
+		case hasQueryBlock(el.Get(0)):
+			collectQueryBlocks(el, blocks)
+			return
+		}
+		text := el.Text()
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		terms := make(map[string]int)
+		length := 0
+		for _, t := range tokenizeQuery(text) {
+			terms[t]++
+			length++
+		}
+		*blocks = append(*blocks, queryBlock{
+			sel:     el,
+			heading: len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6',
+			terms:   terms,
+			length:  length,
+		})
+	})
+}
+
+// hasQueryBlock reports whether n contains a block element.
+func hasQueryBlock(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && (queryBlockTags[c.Data] || hasQueryBlock(c)) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryTerms returns the distinct terms of a query.
+func queryTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, t := range tokenizeQuery(query) {
+		if !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// tokenizeQuery lowercases text and splits it into terms: words without
+// stopwords and plural "s", and overlapping bigrams of CJK runs, which
+// have no spaces between words.
+func tokenizeQuery(text string) []string {
+	var terms []string
+	var word, cjk []rune
+	flushWord := func() {
+		if len(word) == 0 {
+			return
+		}
+		w := string(word)
+		word = word[:0]
+		if queryStopwords[w] {
+			return
+		}
+		if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+			w = w[:len(w)-1]
+		}
+		terms = append(terms, w)
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			terms = append(terms, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			terms = append(terms, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
+
+// isCJK reports whether r is a Han, Hiragana, Katakana or Hangul character.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
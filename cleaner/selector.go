@@ -4,17 +4,19 @@ import (
 	"bytes"
 	"strings"
 
-	"github.com/andybalholm/cascadia"
+	"github.com/use-agent/purify/selector"
 	"golang.org/x/net/html"
 )
 
-// ApplyCSSSelector parses rawHTML, matches elements against the given CSS
+// ApplyCSSSelector parses rawHTML, matches elements against the given
 // selector, and returns the concatenated outer HTML of all matched elements.
+// The selector may be plain CSS or use any engine supported by the selector
+// package (xpath=, text=, role=, nth=, chained with " >> ").
 //
 // If no elements match, the original rawHTML is returned unchanged so that
 // downstream processing still has something to work with.
-func ApplyCSSSelector(rawHTML string, selector string) (string, error) {
-	sel, err := cascadia.Parse(selector)
+func ApplyCSSSelector(rawHTML string, sel string) (string, error) {
+	parsed, err := selector.Parse(sel)
 	if err != nil {
 		return "", err
 	}
@@ -24,7 +26,7 @@ func ApplyCSSSelector(rawHTML string, selector string) (string, error) {
 		return "", err
 	}
 
-	matches := cascadia.QueryAll(doc, sel)
+	matches := parsed.QueryAll(doc)
 	if len(matches) == 0 {
 		// No matches — fall back to original HTML to avoid empty output.
 		return rawHTML, nil
@@ -0,0 +1,153 @@
+package cleaner
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ExpandShadowRoots flattens declarative shadow DOM into light DOM so that
+// readability and pruning see what the browser would render.
+//
+// Every <template shadowrootmode> (or legacy <template shadowroot>) replaces
+// its host's children with the template content, and the host's original
+// light-DOM children are distributed into the template's <slot> elements:
+// named slots take children with a matching slot attribute, the default slot
+// takes the rest, and slots that receive nothing keep their fallback content.
+//
+// HTML without declarative shadow roots is returned unchanged.
+func ExpandShadowRoots(rawHTML string) string {
+	if !strings.Contains(rawHTML, "shadowroot") {
+		return rawHTML
+	}
+	doc, err := html.Parse(strings.NewReader(rawHTML))
+	if err != nil {
+		return rawHTML
+	}
+
+	var templates []*html.Node
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "template" && isShadowTemplate(n) {
+			templates = append(templates, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(doc)
+	if len(templates) == 0 {
+		return rawHTML
+	}
+
+	// Reverse document order flattens nested components before the shadow
+	// trees that contain them.
+	for i := len(templates) - 1; i >= 0; i-- {
+		flattenShadowRoot(templates[i])
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return rawHTML
+	}
+	return buf.String()
+}
+
+// isShadowTemplate reports whether a <template> declares a shadow root.
+func isShadowTemplate(n *html.Node) bool {
+	for _, a := range n.Attr {
+		if a.Key == "shadowrootmode" || a.Key == "shadowroot" {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenShadowRoot replaces the host's children with the template content,
+// distributing the host's light-DOM children into slots.
+func flattenShadowRoot(tmpl *html.Node) {
+	host := tmpl.Parent
+	if host == nil || host.Type != html.ElementNode {
+		return
+	}
+
+	// Detach the light-DOM children (everything except the template).
+	var light []*html.Node
+	for c := host.FirstChild; c != nil; {
+		next := c.NextSibling
+		host.RemoveChild(c)
+		if c != tmpl {
+			light = append(light, c)
+		}
+		c = next
+	}
+
+	// Group light children by slot name; "" is the default slot.
+	assigned := make(map[string][]*html.Node)
+	for _, c := range light {
+		name := ""
+		if c.Type == html.ElementNode {
+			name = attrValue(c, "slot")
+		}
+		assigned[name] = append(assigned[name], c)
+	}
+
+	// Move the shadow content into the host.
+	for c := tmpl.FirstChild; c != nil; {
+		next := c.NextSibling
+		tmpl.RemoveChild(c)
+		host.AppendChild(c)
+		c = next
+	}
+
+	// Fill slots (collect first: filling mutates the tree).
+	var slots []*html.Node
+	var find func(*html.Node)
+	find = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.Data == "slot" {
+				slots = append(slots, c)
+				continue
+			}
+			find(c)
+		}
+	}
+	find(host)
+
+	for _, slot := range slots {
+		name := attrValue(slot, "name")
+		nodes, ok := assigned[name]
+		if !ok {
+			// Nothing assigned: keep the fallback content in place of the slot.
+			replaceWithChildren(slot)
+			continue
+		}
+		delete(assigned, name)
+		for _, n := range nodes {
+			slot.Parent.InsertBefore(n, slot)
+		}
+		slot.Parent.RemoveChild(slot)
+	}
+}
+
+// replaceWithChildren replaces n by its children.
+func replaceWithChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		n.RemoveChild(c)
+		n.Parent.InsertBefore(c, n)
+		c = next
+	}
+	n.Parent.RemoveChild(n)
+}
+
+// attrValue returns the value of an attribute, or "" when absent.
+func attrValue(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -1,6 +1,13 @@
 package cleaner
 
-import "unicode/utf8"
+import (
+	"log/slog"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
 
 // EstimateTokens provides a fast token count estimate without importing tiktoken.
 //
@@ -21,3 +28,49 @@ func EstimateTokens(text string) int {
 	}
 	return est
 }
+
+// Tokenizers accepted by the tokenizer option. "estimate" (the default)
+// is EstimateTokens; the others are the OpenAI BPE vocabularies, embedded
+// in the binary.
+const (
+	TokenizerEstimate = "estimate"
+	TokenizerCL100K   = "cl100k_base" // GPT-4, GPT-3.5, text-embedding-3
+	TokenizerO200K    = "o200k_base"  // GPT-4o, GPT-4.1, o-series
+)
+
+func init() {
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+}
+
+// bpeEncoders holds the BPE encoders, loaded on first use (each takes a few
+// hundred milliseconds and tens of MB).
+var bpeEncoders sync.Map // tokenizer name → *bpeEncoder
+
+type bpeEncoder struct {
+	once sync.Once
+	enc  *tiktoken.Tiktoken
+	err  error
+}
+
+// CountTokens counts the tokens of text with the named tokenizer. Unknown
+// names, and encoders that fail to load, fall back to EstimateTokens.
+func CountTokens(text, tokenizer string) int {
+	if tokenizer == "" || tokenizer == TokenizerEstimate || text == "" {
+		return EstimateTokens(text)
+	}
+	if tokenizer != TokenizerCL100K && tokenizer != TokenizerO200K {
+		return EstimateTokens(text)
+	}
+	v, _ := bpeEncoders.LoadOrStore(tokenizer, &bpeEncoder{})
+	e := v.(*bpeEncoder)
+	e.once.Do(func() {
+		e.enc, e.err = tiktoken.GetEncoding(tokenizer)
+		if e.err != nil {
+			slog.Error("tokenizer: failed to load BPE vocabulary", "tokenizer", tokenizer, "error", e.err)
+		}
+	})
+	if e.err != nil {
+		return EstimateTokens(text)
+	}
+	return len(e.enc.EncodeOrdinary(text))
+}
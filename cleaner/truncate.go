@@ -0,0 +1,218 @@
+package cleaner
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// mdLink matches markdown links and images, capturing the visible text.
+var mdLink = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+
+// elision is the note left where content was removed.
+func elision(tokens int) string {
+	return fmt.Sprintf("[… %d tokens omitted …]", tokens)
+}
+
+// Truncate fits cleaned content into maxTokens (counted with tokenizer)
+// while keeping its structure: the headings outline (including the title)
+// and the lead paragraph stay, and the bodies of the lowest-scoring sections
+// are dropped first, each section's first paragraph last. Every run of
+// removed blocks is replaced by an elision note. Content that still does not
+// fit is cut at the budget.
+//
+// Returns the content and the number of tokens removed (0 when the content
+// already fits).
+func Truncate(content string, maxTokens int, tokenizer string) (string, int) {
+	total := CountTokens(content, tokenizer)
+	if maxTokens <= 0 || total <= maxTokens {
+		return content, 0
+	}
+
+	blocks, _ := markdownBlocks(content)
+	counts := make([]int, len(blocks))
+	for i, b := range blocks {
+		counts[i] = CountTokens(content[b.start:b.end], tokenizer)
+	}
+
+	// Group block indexes by section and find each section's lead.
+	var secs [][]int
+	for i, b := range blocks {
+		if b.heading || len(secs) == 0 {
+			secs = append(secs, nil)
+		}
+		secs[len(secs)-1] = append(secs[len(secs)-1], i)
+	}
+	docLead := -1
+	leads := make([]int, len(secs))
+	for s, sec := range secs {
+		leads[s] = -1
+		for _, i := range sec {
+			if !blocks[i].heading {
+				leads[s] = i
+				break
+			}
+		}
+		if docLead < 0 {
+			docLead = leads[s]
+		}
+	}
+
+	// Lowest-scoring sections first; among equals, later sections first.
+	order := make([]int, len(secs))
+	scores := make([]float64, len(secs))
+	for s, sec := range secs {
+		order[s] = s
+		scores[s] = sectionScore(content, blocks, sec)
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if scores[order[a]] != scores[order[b]] {
+			return scores[order[a]] < scores[order[b]]
+		}
+		return order[a] > order[b]
+	})
+
+	var drops []int
+	for _, s := range order {
+		sec := secs[s]
+		for k := len(sec) - 1; k >= 0; k-- {
+			if i := sec[k]; !blocks[i].heading && i != leads[s] {
+				drops = append(drops, i)
+			}
+		}
+	}
+	for _, s := range order {
+		if leads[s] >= 0 && leads[s] != docLead {
+			drops = append(drops, leads[s])
+		}
+	}
+
+	dropped := make([]bool, len(blocks))
+	kept := total
+	for _, i := range drops {
+		dropped[i] = true
+		kept -= counts[i]
+		if kept > maxTokens {
+			continue
+		}
+		if out := renderKept(content, blocks, counts, dropped); CountTokens(out, tokenizer) <= maxTokens {
+			return out, total - CountTokens(out, tokenizer)
+		}
+	}
+
+	// The outline and lead alone exceed the budget: cut at the budget.
+	out := cutToBudget(renderKept(content, blocks, counts, dropped), total, maxTokens, tokenizer)
+	return out, total - CountTokens(out, tokenizer)
+}
+
+// renderKept joins the kept blocks, replacing each run of dropped blocks
+// with an elision note.
+func renderKept(content string, blocks []span, counts []int, dropped []bool) string {
+	var b strings.Builder
+	run := 0
+	write := func(s string) {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(s)
+	}
+	for i, blk := range blocks {
+		if dropped[i] {
+			run += counts[i]
+			continue
+		}
+		if run > 0 {
+			write(elision(run))
+			run = 0
+		}
+		write(content[blk.start:blk.end])
+	}
+	if run > 0 {
+		write(elision(run))
+	}
+	return b.String()
+}
+
+// cutToBudget keeps the longest prefix of s that fits maxTokens together
+// with a final elision note. The note is left out when the budget is too
+// small to hold it.
+func cutToBudget(s string, total, maxTokens int, tokenizer string) string {
+	budget := maxTokens - CountTokens("\n\n"+elision(total), tokenizer)
+	note := budget > 0
+	if !note {
+		budget = maxTokens
+	}
+	// Binary search on the number of runes kept.
+	lo, hi := 0, utf8.RuneCountInString(s)
+	prefix := func(n int) string {
+		i := 0
+		for k := 0; k < n; k++ {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+		}
+		return s[:i]
+	}
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if CountTokens(prefix(mid), tokenizer) <= budget {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	kept := strings.TrimRight(prefix(lo), " \t\n")
+	if !note {
+		return kept
+	}
+	return kept + "\n\n" + elision(total-CountTokens(kept, tokenizer))
+}
+
+// sectionScore rates a section of cleaned markdown with the pruning scorer:
+// link-heavy sections and sections titled like boilerplate ("Related
+// posts", "Share this", "Comments") score lowest.
+func sectionScore(content string, blocks []span, sec []int) float64 {
+	var sig blockSignals
+	for _, i := range sec {
+		text := content[blocks[i].start:blocks[i].end]
+		sig.totalLen += len(text)
+		visible := mdLink.ReplaceAllStringFunc(text, func(m string) string {
+			if strings.HasPrefix(m, "!") {
+				return ""
+			}
+			anchor := mdLink.FindStringSubmatch(m)[1]
+			sig.linkTextLen += len(strings.TrimSpace(anchor))
+			return anchor
+		})
+		sig.textLen += len(strings.TrimSpace(strings.Trim(visible, "#*_`> -|")))
+		if blocks[i].heading {
+			sig.classIDWeight = headingWeight(text)
+		}
+	}
+	return sig.score()
+}
+
+// headingWeight applies the class/id content patterns to the words of a
+// heading, the closest thing markdown has to a class name.
+func headingWeight(heading string) float64 {
+	words := strings.FieldsFunc(strings.ToLower(heading), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z')
+	})
+	score := 0.0
+	for _, pats := range []struct {
+		list []string
+		w    float64
+	}{{positiveClassIDPatterns, 3.0}, {negativeClassIDPatterns, -3.0}} {
+	match:
+		for _, w := range words {
+			for _, pat := range pats.list {
+				if w == pat || w == pat+"s" {
+					score += pats.w
+					break match
+				}
+			}
+		}
+	}
+	return score
+}
@@ -10,13 +10,16 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/use-agent/purify/adblock"
 	"github.com/use-agent/purify/api"
+	"github.com/use-agent/purify/api/handler"
 	"github.com/use-agent/purify/cache"
 	"github.com/use-agent/purify/cleaner"
 	"github.com/use-agent/purify/config"
 	"github.com/use-agent/purify/engine"
 	"github.com/use-agent/purify/llm"
 	"github.com/use-agent/purify/models"
+	"github.com/use-agent/purify/monitor"
 	"github.com/use-agent/purify/scraper"
 )
 
@@ -41,6 +44,22 @@ func main() {
 	}
 	defer sc.Close()
 
+	// ── 3a. Load ad-blocking filter lists ──────────────────────────
+	var filters *adblock.Engine
+	if len(cfg.Scraper.FilterLists) > 0 {
+		filters, err = adblock.LoadFiles(cfg.Scraper.FilterLists...)
+		if err != nil {
+			slog.Error("failed to load filter lists", "error", err)
+			os.Exit(1)
+		}
+		sc.SetFilterEngine(filters)
+		slog.Info("filter lists loaded",
+			"lists", len(cfg.Scraper.FilterLists),
+			"rules", filters.Rules,
+			"skipped", filters.Skipped,
+		)
+	}
+
 	// ── 3b. Initialise multi-engine dispatcher ─────────────────────
 	if cfg.Engine.EnableMultiEngine {
 		// Rod callback: wraps the scraper's DoScrapeRod (bypasses the dispatcher).
@@ -51,6 +70,21 @@ func main() {
 				Timeout: int(req.Timeout.Seconds()),
 				Stealth: req.Stealth,
 				Headers: req.Headers,
+
+				BlockAds:       req.BlockAds,
+				BlockResources: req.BlockResources,
+				BlockURLs:      req.BlockURLs,
+				AllowURLs:      req.AllowURLs,
+				RemoveHidden:   req.RemoveHidden,
+				LazyLoad:       req.LazyLoad,
+			}
+			if em := req.Emulation; em != nil {
+				scrapeReq.Device = em.Device
+				scrapeReq.Locale = em.Locale
+				scrapeReq.TimezoneID = em.TimezoneID
+				if g := em.Geolocation; g != nil {
+					scrapeReq.Geolocation = &models.Geolocation{Latitude: g.Latitude, Longitude: g.Longitude, Accuracy: g.Accuracy}
+				}
 			}
 			scrapeReq.Defaults()
 
@@ -63,6 +97,8 @@ func main() {
 				Title:      result.Title,
 				StatusCode: result.StatusCode,
 				FinalURL:   result.FinalURL,
+
+				BlockedRequests: result.BlockedRequests,
 			}, nil
 		}
 
@@ -83,6 +119,9 @@ func main() {
 
 	// ── 4. Initialise cleaner ───────────────────────────────────────
 	cl := cleaner.NewCleaner()
+	if filters != nil {
+		cl.SetFilterEngine(filters)
+	}
 
 	// ── 4b. Initialise cache ────────────────────────────────────────
 	cc := cache.New(cfg.Cache.MaxEntries)
@@ -90,9 +129,18 @@ func main() {
 	// ── 4c. Initialise LLM client ───────────────────────────────────
 	llmClient := llm.NewClient(nil)
 
+	// ── 4d. Start the monitor scheduler ────────────────────────────
+	mon := monitor.NewScheduler(handler.MonitorCheck(sc, cl), cfg.Monitor)
+	monCtx, stopMonitors := context.WithCancel(context.Background())
+	monDone := make(chan struct{})
+	go func() {
+		mon.Run(monCtx)
+		close(monDone)
+	}()
+
 	// ── 5. Setup router ─────────────────────────────────────────────
 	startTime := time.Now()
-	router := api.NewRouter(sc, cl, llmClient, cfg, cc, startTime)
+	router := api.NewRouter(sc, cl, llmClient, cfg, cc, mon, startTime)
 
 	// ── 6. Start HTTP server ────────────────────────────────────────
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
@@ -125,6 +173,12 @@ func main() {
 		slog.Info("HTTP server drained gracefully")
 	}
 
+	// Stop scheduling monitor checks and wait for those in flight, which
+	// need the browser.
+	stopMonitors()
+	<-monDone
+	slog.Info("monitor scheduler stopped")
+
 	// sc.Close() runs via defer — drains page pool and kills Chrome.
 	slog.Info("purify stopped")
 }
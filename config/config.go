@@ -18,6 +18,8 @@ type Config struct {
 	Log          LogConfig
 	Engine       EngineConfig
 	AdaptivePool AdaptivePoolConfig
+	Browse       BrowseConfig
+	Monitor      MonitorConfig
 }
 
 // EngineConfig controls the multi-engine racing dispatcher.
@@ -47,6 +49,33 @@ type AdaptivePoolConfig struct {
 	ScaleStep float64 // default: 0.05
 }
 
+// BrowseConfig controls interactive browsing sessions (/api/v1/browse).
+type BrowseConfig struct {
+	// IdleTimeout closes a session that receives no command for this long.
+	IdleTimeout time.Duration // default: 5m
+
+	// MaxSessionsPerKey is the number of sessions an API key (or client IP
+	// when auth is disabled) may hold open at once.
+	MaxSessionsPerKey int // default: 2
+
+	// MaxSessions is the number of sessions open at once across all keys.
+	// It is kept below the page pool size so scrapes always get a tab.
+	MaxSessions int // default: 5
+}
+
+// MonitorConfig controls change monitoring (/api/v1/monitors).
+type MonitorConfig struct {
+	// MinInterval is the shortest re-scrape interval a monitor may use.
+	MinInterval time.Duration // default: 1m
+
+	// MaxPerKey is the number of monitors an API key (or client IP when
+	// auth is disabled) may register.
+	MaxPerKey int // default: 50
+
+	// Concurrency is the number of monitor checks run at once.
+	Concurrency int // default: 2
+}
+
 // CacheConfig controls the scrape response cache.
 type CacheConfig struct {
 	// MaxEntries is the maximum number of cached responses.
@@ -92,6 +121,11 @@ type ScraperConfig struct {
 	// BlockedResourceTypes lists resource types to block.
 	// default: ["Image", "Stylesheet", "Font", "Media"]
 	BlockedResourceTypes []string
+
+	// FilterLists are paths to Adblock Plus syntax filter lists (EasyList,
+	// EasyPrivacy, ...) used by block_ads. When empty, block_ads falls back
+	// to a built-in list of ad domains.
+	FilterLists []string
 }
 
 // AuthConfig controls API key authentication.
@@ -140,6 +174,7 @@ func Load() *Config {
 			BlockedResourceTypes: envSliceOr("PURIFY_BLOCKED_RESOURCES", []string{
 				"Image", "Stylesheet", "Font", "Media",
 			}),
+			FilterLists: envSliceOr("PURIFY_FILTER_LISTS", nil),
 		},
 		Auth: AuthConfig{
 			Enabled: envBoolOr("PURIFY_AUTH_ENABLED", true),
@@ -167,6 +202,16 @@ func Load() *Config {
 			MemThreshold: envFloatOr("PURIFY_MEM_THRESHOLD", 0.9),
 			ScaleStep:    envFloatOr("PURIFY_SCALE_STEP", 0.05),
 		},
+		Browse: BrowseConfig{
+			IdleTimeout:       envDurationOr("PURIFY_BROWSE_IDLE_TIMEOUT", 5*time.Minute),
+			MaxSessionsPerKey: envIntOr("PURIFY_BROWSE_MAX_SESSIONS", 2),
+			MaxSessions:       envIntOr("PURIFY_BROWSE_MAX_TOTAL_SESSIONS", 5),
+		},
+		Monitor: MonitorConfig{
+			MinInterval: envDurationOr("PURIFY_MONITOR_MIN_INTERVAL", time.Minute),
+			MaxPerKey:   envIntOr("PURIFY_MONITOR_MAX_PER_KEY", 50),
+			Concurrency: envIntOr("PURIFY_MONITOR_CONCURRENCY", 2),
+		},
 	}
 }
 
@@ -0,0 +1,98 @@
+package engine
+
+import "strings"
+
+// Device is a device profile for emulation.
+type Device struct {
+	Width             int
+	Height            int
+	DeviceScaleFactor float64
+	Mobile            bool
+	HasTouch          bool
+	UserAgent         string
+	// Platform is the navigator.platform value and the Sec-CH-UA-Platform
+	// client hint (without quotes).
+	Platform string
+}
+
+// Devices are the presets accepted by ScrapeRequest.Device.
+var Devices = map[string]Device{
+	"desktop": {
+		Width: 1920, Height: 1080, DeviceScaleFactor: 1,
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
+		Platform:  "Windows",
+	},
+	"iphone-15": {
+		Width: 393, Height: 852, DeviceScaleFactor: 3, Mobile: true, HasTouch: true,
+		UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1",
+		Platform:  "iOS",
+	},
+	"iphone-se": {
+		Width: 375, Height: 667, DeviceScaleFactor: 2, Mobile: true, HasTouch: true,
+		UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1",
+		Platform:  "iOS",
+	},
+	"pixel-7": {
+		Width: 412, Height: 915, DeviceScaleFactor: 2.625, Mobile: true, HasTouch: true,
+		UserAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36",
+		Platform:  "Android",
+	},
+	"galaxy-s23": {
+		Width: 360, Height: 780, DeviceScaleFactor: 3, Mobile: true, HasTouch: true,
+		UserAgent: "Mozilla/5.0 (Linux; Android 14; SM-S911B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36",
+		Platform:  "Android",
+	},
+	"ipad": {
+		Width: 820, Height: 1180, DeviceScaleFactor: 2, Mobile: true, HasTouch: true,
+		UserAgent: "Mozilla/5.0 (iPad; CPU OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1",
+		Platform:  "iOS",
+	},
+}
+
+// Geolocation is an emulated position.
+type Geolocation struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64 // meters
+}
+
+// Emulation describes how the fetched page should see the client. Browser
+// engines apply all of it; the HTTP engine can only send matching headers.
+type Emulation struct {
+	Device      string // key of Devices; "" keeps the default
+	Locale      string // BCP 47 tag, e.g. "de-DE"
+	TimezoneID  string // IANA name, e.g. "Europe/Berlin"
+	Geolocation *Geolocation
+}
+
+// Headers returns the request headers matching the emulated device and
+// locale. Safe to call on a nil Emulation.
+func (em *Emulation) Headers() map[string]string {
+	headers := make(map[string]string)
+	if em == nil {
+		return headers
+	}
+	if d, ok := Devices[em.Device]; ok {
+		headers["User-Agent"] = d.UserAgent
+		headers["Sec-CH-UA-Platform"] = `"` + d.Platform + `"`
+		if d.Mobile {
+			headers["Sec-CH-UA-Mobile"] = "?1"
+		} else {
+			headers["Sec-CH-UA-Mobile"] = "?0"
+		}
+	}
+	if em.Locale != "" {
+		headers["Accept-Language"] = AcceptLanguage(em.Locale)
+	}
+	return headers
+}
+
+// AcceptLanguage builds an Accept-Language value for a locale, falling back
+// from the regional tag to its language: "de-DE" → "de-DE,de;q=0.9".
+func AcceptLanguage(locale string) string {
+	lang, _, found := strings.Cut(locale, "-")
+	if !found {
+		return locale
+	}
+	return locale + "," + lang + ";q=0.9"
+}
@@ -22,6 +22,25 @@ type FetchRequest struct {
 	Cookies []http.Cookie
 	Timeout time.Duration
 	Stealth bool
+
+	// Request blocking rules, honored by browser engines only (see
+	// models.ScrapeRequest for their semantics).
+	BlockAds       bool
+	BlockResources []string
+	BlockURLs      []string
+	AllowURLs      []string
+
+	// RemoveHidden makes browser engines mark elements hidden by layout
+	// for the cleaner to strip.
+	RemoveHidden bool
+
+	// LazyLoad makes browser engines scroll through the page to trigger
+	// lazy-loaded content before capture.
+	LazyLoad bool
+
+	// Emulation is the device, locale, timezone and position to present.
+	// Nil keeps the engine defaults.
+	Emulation *Emulation
 }
 
 // FetchResult is the output of a successful engine fetch.
@@ -31,4 +50,7 @@ type FetchResult struct {
 	StatusCode int
 	FinalURL   string
 	EngineName string
+
+	// BlockedRequests is the number of requests a browser engine blocked.
+	BlockedRequests int
 }
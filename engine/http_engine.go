@@ -214,6 +214,11 @@ func (e *HTTPEngine) Fetch(ctx context.Context, req *FetchRequest) (*FetchResult
 	httpReq.Header.Set("Accept-Language", "en-US,en;q=0.9")
 	httpReq.Header.Set("Accept-Encoding", "identity") // no compression for simplicity
 
+	// Match the emulated device and locale.
+	for k, v := range req.Emulation.Headers() {
+		httpReq.Header.Set(k, v)
+	}
+
 	// Apply custom headers (override defaults if provided).
 	for k, v := range req.Headers {
 		httpReq.Header.Set(k, v)
@@ -20,6 +20,9 @@ type BatchOptions struct {
 	WaitForNetworkIdle *bool  `json:"wait_for_network_idle,omitempty"`
 	Timeout            int    `json:"timeout,omitempty" binding:"omitempty,min=1,max=120"`
 	Stealth            bool   `json:"stealth,omitempty"`
+
+	Chunking  *ChunkingOptions `json:"chunking,omitempty"`
+	Tokenizer string           `json:"tokenizer,omitempty" binding:"omitempty,oneof=estimate cl100k_base o200k_base"`
 }
 
 // BatchResponse is the immediate response for POST /api/v1/batch/scrape.
@@ -0,0 +1,83 @@
+package models
+
+// BrowseCommand is a message sent by the client on the /api/v1/browse
+// WebSocket.
+type BrowseCommand struct {
+	// ID is echoed in the command's result or error event.
+	ID string `json:"id,omitempty"`
+
+	// Type is the command: "navigate", "actions", "snapshot", "screenshot"
+	// or "close".
+	Type string `json:"type" binding:"required,oneof=navigate actions snapshot screenshot close"`
+
+	// URL is the page to load (navigate).
+	URL string `json:"url,omitempty" binding:"omitempty,url"`
+
+	// Actions are run in order on the current page (actions). Refs from an
+	// earlier snapshot's interactive_elements stay valid.
+	Actions []Action `json:"actions,omitempty" binding:"omitempty,max=50,dive"`
+
+	// Format is the snapshot output: "markdown" (default), "html", "text",
+	// "markdown_citations" or "accessibility".
+	Format string `json:"format,omitempty" binding:"omitempty,oneof=markdown html text markdown_citations accessibility"`
+
+	// ExtractMode is the snapshot extraction strategy: "readability"
+	// (default), "raw", "pruning" or "auto".
+	ExtractMode string `json:"extract_mode,omitempty" binding:"omitempty,oneof=readability raw pruning auto"`
+
+	// InteractiveElements adds the page's interactive elements to a
+	// snapshot.
+	InteractiveElements bool `json:"interactive_elements,omitempty"`
+
+	// FullPage captures the whole page instead of the viewport (screenshot).
+	FullPage bool `json:"full_page,omitempty"`
+
+	// ImageFormat is the screenshot encoding: "png" (default) or "jpeg".
+	ImageFormat string `json:"image_format,omitempty" binding:"omitempty,oneof=png jpeg"`
+
+	// Quality is the JPEG quality (screenshot).
+	Quality int `json:"quality,omitempty" binding:"omitempty,min=1,max=100"`
+
+	// Timeout is the command deadline in seconds. Default: 30.
+	Timeout int `json:"timeout,omitempty" binding:"omitempty,min=1,max=120"`
+}
+
+// BrowseEvent is a message sent by the server on the /api/v1/browse
+// WebSocket.
+type BrowseEvent struct {
+	// Type is "ready", "result", "error", "navigated", "console", "dialog"
+	// or "closed".
+	Type string `json:"type"`
+
+	// ID and Command identify the command a result or error answers.
+	ID      string `json:"id,omitempty"`
+	Command string `json:"command,omitempty"`
+
+	// SessionID is sent with "ready".
+	SessionID string `json:"session_id,omitempty"`
+
+	URL        string `json:"url,omitempty"`
+	Title      string `json:"title,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+
+	// Content is the snapshot in the requested format.
+	Content string     `json:"content,omitempty"`
+	Tokens  *TokenInfo `json:"tokens,omitempty"`
+
+	InteractiveElements []InteractiveElement `json:"interactive_elements,omitempty"`
+
+	// Screenshot is the base64-encoded image; MIMEType is its type.
+	Screenshot string `json:"screenshot,omitempty"`
+	MIMEType   string `json:"mime_type,omitempty"`
+
+	// Level is the console message level; Dialog the dialog type.
+	Level   string `json:"level,omitempty"`
+	Dialog  string `json:"dialog,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// Reason explains a "closed" event: "client", "idle_timeout" or
+	// "error".
+	Reason string `json:"reason,omitempty"`
+
+	Error *ErrorDetail `json:"error,omitempty"`
+}
@@ -24,6 +24,26 @@ type CrawlRequest struct {
 	// Options contains shared scrape options for each crawled page.
 	Options CrawlOptions `json:"options"`
 
+	// Dedupe handles pages whose content fingerprint is within
+	// DedupeThreshold bits of a page already crawled (print versions,
+	// tracking-parameter variants, mirrored locales): "skip" returns only
+	// their metadata and does not follow their links; "flag" keeps the full
+	// result. Both report duplicate_of.
+	Dedupe string `json:"dedupe,omitempty" binding:"omitempty,oneof=skip flag"`
+
+	// DedupeThreshold is the maximum Hamming distance between the
+	// fingerprints of duplicates. Default: 3.
+	DedupeThreshold int `json:"dedupe_threshold,omitempty" binding:"omitempty,min=1,max=32"`
+
+	// RemoveBoilerplate learns the blocks repeated across the site's pages
+	// (header, footer, cookie text, sidebars) and strips them from every
+	// result. The learned blocks are cached per host for later scrapes.
+	RemoveBoilerplate bool `json:"remove_boilerplate,omitempty"`
+
+	// BoilerplateThreshold is the share of crawled pages, in percent, a
+	// block must appear on to count as boilerplate. Default: 50.
+	BoilerplateThreshold int `json:"boilerplate_threshold,omitempty" binding:"omitempty,min=1,max=100"`
+
 	WebhookURL    string `json:"webhook_url,omitempty" binding:"omitempty,url"`
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
@@ -32,6 +52,9 @@ type CrawlRequest struct {
 type CrawlOptions struct {
 	OutputFormat string `json:"output_format,omitempty" binding:"omitempty,oneof=markdown html text"`
 	ExtractMode  string `json:"extract_mode,omitempty" binding:"omitempty,oneof=readability raw"`
+
+	Chunking  *ChunkingOptions `json:"chunking,omitempty"`
+	Tokenizer string           `json:"tokenizer,omitempty" binding:"omitempty,oneof=estimate cl100k_base o200k_base"`
 }
 
 // CrawlResponse is the immediate response for POST /api/v1/crawl.
@@ -46,6 +46,9 @@ type ExtractRequest struct {
 	// ProxyURL overrides the default proxy for this request.
 	ProxyURL string `json:"proxy_url,omitempty" binding:"omitempty,url"`
 
+	// MaxTokens caps the cleaned content sent to the LLM, trimmed as in
+	// ScrapeRequest.MaxTokens.
+	MaxTokens int `json:"max_tokens,omitempty" binding:"omitempty,min=1"`
 }
 
 // Defaults applies default values to unset fields.
@@ -99,6 +102,11 @@ type ExtractResponse struct {
 	// Tokens provides token estimates for the scrape pipeline.
 	Tokens TokenInfo `json:"tokens"`
 
+	// Truncated reports that max_tokens elided part of the content before
+	// extraction; TruncatedTokens is how many tokens were removed.
+	Truncated       bool `json:"truncated,omitempty"`
+	TruncatedTokens int  `json:"truncated_tokens,omitempty"`
+
 	// Timing provides duration breakdowns for the operation.
 	Timing ExtractTimingInfo `json:"timing"`
 
@@ -111,10 +119,10 @@ type ExtractResponse struct {
 
 // ExtractTimingInfo extends TimingInfo with extraction timing.
 type ExtractTimingInfo struct {
-	TotalMs        int64 `json:"total_ms"`
-	NavigationMs   int64 `json:"navigation_ms"`
-	CleaningMs     int64 `json:"cleaning_ms"`
-	ExtractionMs   int64 `json:"extraction_ms"`
+	TotalMs      int64 `json:"total_ms"`
+	NavigationMs int64 `json:"navigation_ms"`
+	CleaningMs   int64 `json:"cleaning_ms"`
+	ExtractionMs int64 `json:"extraction_ms"`
 }
 
 // LLMUsage reports token consumption from the LLM call.
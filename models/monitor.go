@@ -0,0 +1,96 @@
+package models
+
+// MonitorRequest is the payload for POST /api/v1/monitors.
+type MonitorRequest struct {
+	// URL is the page to watch. Required.
+	URL string `json:"url" binding:"required,url"`
+
+	// Interval is the time between checks in seconds. Default: 3600.
+	// Max: 604800 (one week).
+	Interval int `json:"interval,omitempty" binding:"omitempty,min=1,max=604800"`
+
+	// Options are the scrape settings used for every check.
+	Options MonitorOptions `json:"options"`
+
+	// CSSSelector limits the compared content to the matching elements.
+	CSSSelector string `json:"css_selector,omitempty"`
+
+	// Threshold is the SimHash distance (in bits) a change must exceed to
+	// be reported. Default: 0 (report any change to the content).
+	Threshold int `json:"threshold,omitempty" binding:"omitempty,min=0,max=64"`
+
+	// WebhookURL receives a monitor.changed event for every reported
+	// change.
+	WebhookURL    string `json:"webhook_url,omitempty" binding:"omitempty,url"`
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+}
+
+// MonitorOptions are the scrape settings of a monitor.
+type MonitorOptions struct {
+	OutputFormat       string `json:"output_format,omitempty" binding:"omitempty,oneof=markdown html text"`
+	ExtractMode        string `json:"extract_mode,omitempty" binding:"omitempty,oneof=readability raw pruning auto"`
+	WaitForNetworkIdle *bool  `json:"wait_for_network_idle,omitempty"`
+	Timeout            int    `json:"timeout,omitempty" binding:"omitempty,min=1,max=120"`
+	Stealth            bool   `json:"stealth,omitempty"`
+}
+
+// Monitor is a registered URL and the state of its checks.
+type Monitor struct {
+	ID          string         `json:"id"`
+	URL         string         `json:"url"`
+	Interval    int            `json:"interval"`
+	Options     MonitorOptions `json:"options"`
+	CSSSelector string         `json:"css_selector,omitempty"`
+	Threshold   int            `json:"threshold"`
+	WebhookURL  string         `json:"webhook_url,omitempty"`
+	CreatedAt   int64          `json:"created_at"` // unix timestamp
+
+	// LastCheckedAt and NextCheckAt are unix timestamps; LastChangedAt is
+	// when a change was last reported.
+	LastCheckedAt int64 `json:"last_checked_at,omitempty"`
+	LastChangedAt int64 `json:"last_changed_at,omitempty"`
+	NextCheckAt   int64 `json:"next_check_at"`
+
+	// Checks and Changes count completed checks and reported changes.
+	Checks  int `json:"checks"`
+	Changes int `json:"changes"`
+
+	// Fingerprint is the SimHash of the content changes are compared
+	// against: the first check's, then the last reported change's.
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	// LastChange is the most recently reported change.
+	LastChange *MonitorChange `json:"last_change,omitempty"`
+
+	// LastError is set when the last check failed.
+	LastError *ErrorDetail `json:"last_error,omitempty"`
+
+	// Owner is the API key (or client IP) that registered the monitor.
+	Owner         string `json:"-"`
+	WebhookSecret string `json:"-"`
+}
+
+// MonitorChange describes a reported change; it is the data of a
+// monitor.changed webhook event.
+type MonitorChange struct {
+	MonitorID string `json:"monitor_id"`
+	URL       string `json:"url"`
+	CheckedAt int64  `json:"checked_at"` // unix timestamp
+
+	// PreviousFingerprint and Fingerprint are the SimHashes of the old
+	// and new content; Distance is the number of differing bits.
+	PreviousFingerprint string `json:"previous_fingerprint"`
+	Fingerprint         string `json:"fingerprint"`
+	Distance            int    `json:"distance"`
+
+	// Added and Removed count changed lines; Diff is the unified line
+	// diff from the old content to the new.
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+	Diff    string `json:"diff"`
+}
+
+// MonitorListResponse is the response for GET /api/v1/monitors.
+type MonitorListResponse struct {
+	Monitors []Monitor `json:"monitors"`
+}
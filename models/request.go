@@ -1,5 +1,7 @@
 package models
 
+import "encoding/json"
+
 // ScrapeRequest is the payload for POST /api/v1/scrape.
 type ScrapeRequest struct {
 	// URL is the target page to scrape. Required.
@@ -25,16 +27,19 @@ type ScrapeRequest struct {
 	ProxyURL string `json:"proxy_url,omitempty" binding:"omitempty,url"`
 
 	// OutputFormat controls the response body format.
-	// Allowed: "markdown" (default), "html", "text".
-	OutputFormat string `json:"output_format,omitempty" binding:"omitempty,oneof=markdown html text markdown_citations"`
+	// Allowed: "markdown" (default), "html", "text", "markdown_citations",
+	// "accessibility" (indented accessibility tree; requires the browser).
+	OutputFormat string `json:"output_format,omitempty" binding:"omitempty,oneof=markdown html text markdown_citations accessibility"`
 
 	// ExtractMode controls the content extraction strategy.
 	// "readability" (default): two-stage pipeline, readability extracts main body → format conversion.
 	// "raw": skip readability, pass full rendered HTML directly to format conversion.
 	ExtractMode string `json:"extract_mode,omitempty" binding:"omitempty,oneof=readability raw pruning auto"`
 
-	// CSSSelector is an optional CSS selector to filter HTML before cleaning.
-	// When set, only the matched elements' outer HTML is passed to the pipeline.
+	// CSSSelector is an optional selector to filter HTML before cleaning.
+	// Plain CSS or a prefixed engine (xpath=, text=, role=, nth=; chain with
+	// " >> "). When set, only the matched elements' outer HTML is passed to
+	// the pipeline.
 	CSSSelector string `json:"css_selector,omitempty"`
 
 	// Headers sets custom HTTP headers for the request.
@@ -48,12 +53,73 @@ type ScrapeRequest struct {
 	// the page loads and before extracting content. Max 50 actions.
 	Actions []Action `json:"actions,omitempty" binding:"omitempty,max=50,dive"`
 
-	// IncludeTags is a list of CSS selectors. When non-empty, only elements
-	// matching these selectors are kept in the output.
+	// InteractiveElements lists the visible links, buttons and form
+	// controls of the final page with refs that actions can target.
+	// Requires the browser.
+	InteractiveElements bool `json:"interactive_elements,omitempty"`
+
+	// Chunking splits the cleaned content into chunks for retrieval,
+	// returned in chunks[]. Nil disables chunking.
+	Chunking *ChunkingOptions `json:"chunking,omitempty"`
+
+	// Tokenizer counts tokens with a real BPE vocabulary ("cl100k_base" or
+	// "o200k_base") in addition to the fast estimate. Chunk sizes use it
+	// too. Default: "estimate".
+	Tokenizer string `json:"tokenizer,omitempty" binding:"omitempty,oneof=estimate cl100k_base o200k_base"`
+
+	// Query keeps only the content blocks relevant to it, ranked locally
+	// with BM25 and the pruning density signals.
+	Query string `json:"query,omitempty" binding:"omitempty,max=1000"`
+
+	// QueryNeighbors also keeps this many blocks before and after each
+	// relevant block. Default: 0.
+	QueryNeighbors int `json:"query_neighbors,omitempty" binding:"omitempty,min=0,max=5"`
+
+	// RemoveBoilerplate strips the blocks an earlier crawl with
+	// remove_boilerplate learned as the site's boilerplate. A no-op when
+	// nothing was learned for the host.
+	RemoveBoilerplate bool `json:"remove_boilerplate,omitempty"`
+
+	// MaxTokens caps the cleaned content at this many tokens (counted with
+	// Tokenizer). Longer content keeps its title, headings and lead
+	// paragraphs; the lowest-scoring sections are elided first.
+	MaxTokens int `json:"max_tokens,omitempty" binding:"omitempty,min=1"`
+
+	// Dialogs answers JavaScript alert/confirm/prompt dialogs automatically:
+	// "accept" or "dismiss". When empty, dialogs block the page until the
+	// action times out.
+	Dialogs string `json:"dialogs,omitempty" binding:"omitempty,oneof=accept dismiss"`
+
+	// FollowPopups extracts content from the newest tab opened by the page
+	// (target=_blank links, window.open) instead of the original page.
+	// Popup tabs are always closed after the scrape.
+	FollowPopups bool `json:"follow_popups,omitempty"`
+
+	// Downloads captures files downloaded during the browser session (for
+	// example by a "click" action on an export button). Nil disables
+	// downloads. Rejected together with CDPURL.
+	Downloads *DownloadOptions `json:"downloads,omitempty" binding:"excluded_with=CDPURL"`
+
+	// Iframes inlines the content of embedded frames into the extracted HTML.
+	// Nil leaves iframes as empty placeholders.
+	Iframes *IframeOptions `json:"iframes,omitempty"`
+
+	// FlattenShadowDOM serializes open and closed shadow roots into the
+	// captured HTML so web-component content reaches the cleaner. Browser
+	// path only; declarative shadow roots in fetched HTML are always expanded.
+	FlattenShadowDOM bool `json:"flatten_shadow_dom,omitempty"`
+
+	// ActionsRecording is a Chrome DevTools Recorder (Puppeteer Replay) JSON
+	// export. Its steps are translated into Actions and run before any
+	// explicit Actions.
+	ActionsRecording json.RawMessage `json:"actions_recording,omitempty"`
+
+	// IncludeTags is a list of selectors (same syntax as CSSSelector). When
+	// non-empty, only elements matching these selectors are kept in the output.
 	IncludeTags []string `json:"include_tags,omitempty"`
 
-	// ExcludeTags is a list of CSS selectors. Matching elements are removed
-	// from the DOM before content extraction.
+	// ExcludeTags is a list of selectors (same syntax as CSSSelector).
+	// Matching elements are removed from the DOM before content extraction.
 	ExcludeTags []string `json:"exclude_tags,omitempty"`
 
 	// OnlyMainContent is a Firecrawl-compatible alias. When explicitly set
@@ -64,30 +130,166 @@ type ScrapeRequest struct {
 	// by injecting JS after page load.
 	RemoveOverlays bool `json:"remove_overlays,omitempty"`
 
-	// BlockAds blocks requests to known ad/tracking domains.
+	// LazyLoad scrolls through the page before capture so lazy-loaded
+	// images and content (IntersectionObserver, loading="lazy") load.
+	// Browser sessions only; lazy image attributes (data-src, srcset,
+	// <noscript> fallbacks) are resolved by the cleaner regardless.
+	LazyLoad bool `json:"lazy_load,omitempty"`
+
+	// RemoveHidden strips content that is not rendered: the browser marks
+	// elements hidden by computed style or positioned off-screen, and fetched
+	// HTML is filtered by the hidden/aria-hidden attributes, inline styles and
+	// screen-reader-only classes. Computed styles only reflect class-based
+	// hiding when stylesheets load, which the server blocks by default: pass
+	// BlockResources without "Stylesheet".
+	RemoveHidden bool `json:"remove_hidden,omitempty"`
+
+	// Consent answers the cookie-consent banner of known consent managers
+	// (OneTrust, Didomi, Quantcast, TrustArc, Usercentrics, IAB TCF CMPs):
+	// "accept" or "reject". When no known CMP is found, overlays are removed
+	// as with RemoveOverlays. Browser sessions only.
+	Consent string `json:"consent,omitempty" binding:"omitempty,oneof=accept reject"`
+
+	// BlockAds blocks requests to known ad/tracking domains. With filter
+	// lists configured, their network rules decide what is blocked and their
+	// element-hiding rules are applied by the cleaner.
 	BlockAds bool `json:"block_ads,omitempty"`
 
+	// BlockResources overrides the server's blocked resource types for this
+	// request (case-insensitive): "Image", "Stylesheet", "Font", "Media",
+	// "Script", "XHR", "Fetch", "EventSource", "WebSocket", "Manifest",
+	// "TextTrack", "Ping", "Other". An empty list blocks no resource type;
+	// omit it to keep the server default (none with CDPURL). Browser
+	// sessions only.
+	BlockResources []string `json:"block_resources,omitempty"`
+
+	// BlockURLs blocks requests whose full URL matches one of these glob
+	// patterns ("*" matches any characters, "?" a single one), e.g.
+	// "*://*.analytics.example/*". Browser sessions only.
+	BlockURLs []string `json:"block_urls,omitempty"`
+
+	// AllowURLs exempts matching requests (same glob syntax as BlockURLs)
+	// from every blocking rule, including BlockResources and BlockAds.
+	AllowURLs []string `json:"allow_urls,omitempty"`
+
+	// Device emulates a device preset (viewport, pixel ratio, touch, user
+	// agent): "desktop", "iphone-15", "iphone-se", "pixel-7", "galaxy-s23"
+	// or "ipad". The HTTP engine only sends the matching user agent.
+	Device string `json:"device,omitempty" binding:"omitempty,oneof=desktop iphone-15 iphone-se pixel-7 galaxy-s23 ipad"`
+
+	// Locale sets the browser locale and Accept-Language, e.g. "de-DE".
+	Locale string `json:"locale,omitempty" binding:"omitempty,bcp47_language_tag"`
+
+	// TimezoneID overrides the browser timezone with an IANA name, e.g.
+	// "Europe/Berlin". Browser sessions only.
+	TimezoneID string `json:"timezone_id,omitempty"`
+
+	// Geolocation overrides the position reported by the Geolocation API
+	// and grants the page permission to read it. Browser sessions only.
+	Geolocation *Geolocation `json:"geolocation,omitempty"`
+
 	// CDPURL connects to a user-provided Chrome DevTools Protocol endpoint
 	// instead of using the shared browser pool.
 	CDPURL string `json:"cdp_url,omitempty"`
 
 	// MaxAge is the cache max age in milliseconds. If > 0, the response
 	// may be served from cache if a cached entry exists within this age.
-	// Default: 0 (no caching).
+	// Default: 0 (no caching). Requests with Downloads are never cached.
 	MaxAge int `json:"max_age,omitempty" binding:"omitempty,min=0"`
+
+	// Paginate follows "next page" links or "Load more" buttons and stitches
+	// the pages into a single response. Nil disables pagination.
+	Paginate *PaginateOptions `json:"paginate,omitempty"`
+}
+
+// Geolocation is an emulated position.
+type Geolocation struct {
+	Latitude  float64 `json:"latitude" binding:"min=-90,max=90"`
+	Longitude float64 `json:"longitude" binding:"min=-180,max=180"`
+
+	// Accuracy is the reported accuracy in meters. Default: 100.
+	Accuracy float64 `json:"accuracy,omitempty" binding:"omitempty,min=0"`
+}
+
+// PaginateOptions controls how a scrape follows multi-page content.
+type PaginateOptions struct {
+	// NextSelector selects the "next page" link or "Load more" button, in
+	// the same syntax as action selectors (CSS, xpath=, text=, role=, nth=,
+	// chained with " >> "). When empty, the next page is detected
+	// automatically from rel=next, common pager patterns and "Load more"
+	// buttons.
+	NextSelector string `json:"next_selector,omitempty"`
+
+	// MaxPages is the maximum number of pages to capture, including the first.
+	// Default: 5. Max: 50.
+	MaxPages int `json:"max_pages,omitempty" binding:"omitempty,min=1,max=50"`
+
+	// Combine controls how the pages are returned.
+	// "concat" (default): Content holds all pages joined with page separators.
+	// "pages": each page is returned separately in the response's Pages array.
+	// Rejected together with MaxTokens, RemoveBoilerplate or Chunking, which
+	// work on the single Content.
+	Combine string `json:"combine,omitempty" binding:"omitempty,oneof=concat pages"`
+}
+
+// DownloadOptions controls how files downloaded during a scrape are returned.
+type DownloadOptions struct {
+	// MaxSizeMB caps the size of each returned file. Larger files are listed
+	// without content. Default: 10. Max: 50.
+	MaxSizeMB int `json:"max_size_mb,omitempty" binding:"omitempty,min=1,max=50"`
+
+	// Ingest runs HTML, text and PDF downloads through the cleaning pipeline
+	// and returns the extracted content alongside the raw file.
+	Ingest bool `json:"ingest,omitempty"`
+}
+
+// IframeOptions controls which iframes are inlined. At most 20 frames are
+// inlined per scrape, across all nesting levels.
+type IframeOptions struct {
+	// MaxDepth is how many levels of nested frames are inlined. Default: 1. Max: 3.
+	MaxDepth int `json:"max_depth,omitempty" binding:"omitempty,min=1,max=3"`
+
+	// Origins selects which frames may be inlined relative to the page:
+	// "same-origin", "same-site" (default) or "all".
+	Origins string `json:"origins,omitempty" binding:"omitempty,oneof=same-origin same-site all"`
+
+	// Allow restricts inlining to frames whose host matches one of these
+	// glob patterns (e.g. "*.example.com"). Empty allows any host that
+	// passes Origins.
+	Allow []string `json:"allow,omitempty"`
+
+	// FetchHTTP lets the HTTP engine fetch iframe src URLs itself instead of
+	// requiring a browser session. Frames fetched this way are not rendered.
+	FetchHTTP bool `json:"fetch_http,omitempty"`
 }
 
 // Action represents a single browser interaction in the actions pipeline.
 type Action struct {
-	// Type is the action kind: "wait", "click", "scroll", "execute_js", "scrape".
-	Type string `json:"type" binding:"required,oneof=wait click scroll execute_js scrape"`
+	// Type is the action kind: "wait", "click", "scroll", "execute_js",
+	// "scrape", "navigate", "type", "press", "hover", "set_viewport".
+	Type string `json:"type" binding:"required,oneof=wait click scroll execute_js scrape navigate type press hover set_viewport"`
 
-	// Selector is a CSS selector (used by "wait" and "click").
+	// Selector targets an element (used by "wait", "click", "type" and
+	// "hover"). Plain CSS or a prefixed engine: xpath=, text=, role=, nth=,
+	// chained with " >> ".
 	Selector string `json:"selector,omitempty"`
 
+	// FallbackSelectors are tried alongside Selector; the first one that
+	// matches an element is used.
+	FallbackSelectors []string `json:"fallback_selectors,omitempty"`
+
+	// Ref targets an element by the ref listed in interactive_elements,
+	// taking precedence over Selector (used by "wait", "click", "type" and
+	// "hover"). Refs stay valid for the rest of the browser session.
+	Ref int `json:"ref,omitempty" binding:"omitempty,min=1"`
+
 	// Milliseconds is the wait duration (used by "wait" when Selector is empty).
 	Milliseconds int `json:"milliseconds,omitempty"`
 
+	// Expression is a JavaScript expression to wait for until it is truthy
+	// (used by "wait" when Selector is empty).
+	Expression string `json:"expression,omitempty"`
+
 	// Direction is the scroll direction: "up" or "down" (used by "scroll").
 	Direction string `json:"direction,omitempty" binding:"omitempty,oneof=up down"`
 
@@ -96,6 +298,47 @@ type Action struct {
 
 	// Code is the JavaScript to execute (used by "execute_js").
 	Code string `json:"code,omitempty"`
+
+	// ClickCount is the number of clicks, e.g. 2 for a double click
+	// (used by "click"). Default: 1.
+	ClickCount int `json:"click_count,omitempty" binding:"omitempty,min=1,max=3"`
+
+	// URL is the address to load (used by "navigate").
+	URL string `json:"url,omitempty" binding:"omitempty,url"`
+
+	// Text replaces the value of the target field (used by "type").
+	Text string `json:"text,omitempty"`
+
+	// Key is a key name such as "Enter", "a" or "Control+a"
+	// (used by "press").
+	Key string `json:"key,omitempty"`
+
+	// Viewport is the emulated screen (used by "set_viewport").
+	Viewport *Viewport `json:"viewport,omitempty"`
+}
+
+// Viewport describes an emulated browser viewport.
+type Viewport struct {
+	Width             int     `json:"width" binding:"required,min=1"`
+	Height            int     `json:"height" binding:"required,min=1"`
+	DeviceScaleFactor float64 `json:"device_scale_factor,omitempty"`
+	Mobile            bool    `json:"mobile,omitempty"`
+	HasTouch          bool    `json:"has_touch,omitempty"`
+}
+
+// ChunkingOptions controls how cleaned content is split into chunks.
+type ChunkingOptions struct {
+	// Strategy is "heading" (one chunk per section), "token" (fixed-size
+	// windows), "sentence" or "recursive" (paragraphs, then lines,
+	// sentences and words). Default: "recursive".
+	Strategy string `json:"strategy,omitempty" binding:"omitempty,oneof=heading token sentence recursive"`
+
+	// TargetTokens is the maximum chunk size. Default: 512.
+	TargetTokens int `json:"target_tokens,omitempty" binding:"omitempty,min=16,max=8192"`
+
+	// OverlapTokens is how much of the previous chunk each chunk repeats,
+	// capped at half of TargetTokens. Default: 0.
+	OverlapTokens int `json:"overlap_tokens,omitempty" binding:"omitempty,min=0,max=4096"`
 }
 
 // Cookie represents a browser cookie to set before scraping.
@@ -126,4 +369,26 @@ func (r *ScrapeRequest) Defaults() {
 	if r.OnlyMainContent != nil && !*r.OnlyMainContent {
 		r.ExtractMode = "raw"
 	}
+	if r.Downloads != nil && r.Downloads.MaxSizeMB == 0 {
+		r.Downloads.MaxSizeMB = 10
+	}
+	if r.Geolocation != nil && r.Geolocation.Accuracy == 0 {
+		r.Geolocation.Accuracy = 100
+	}
+	if r.Iframes != nil {
+		if r.Iframes.MaxDepth == 0 {
+			r.Iframes.MaxDepth = 1
+		}
+		if r.Iframes.Origins == "" {
+			r.Iframes.Origins = "same-site"
+		}
+	}
+	if r.Paginate != nil {
+		if r.Paginate.MaxPages == 0 {
+			r.Paginate.MaxPages = 5
+		}
+		if r.Paginate.Combine == "" {
+			r.Paginate.Combine = "concat"
+		}
+	}
 }
@@ -40,10 +40,114 @@ type ScrapeResponse struct {
 	// (e.g. "http", "rod", "rod-stealth"). Empty when multi-engine is disabled.
 	EngineUsed string `json:"engine_used,omitempty"`
 
+	// Pages holds the per-page output of a paginated scrape when
+	// paginate.combine is "pages". Content is left empty in that mode.
+	Pages []PageContent `json:"pages,omitempty"`
+
+	// Downloads lists the files downloaded during the browser session when
+	// the request enabled downloads.
+	Downloads []DownloadedFile `json:"downloads,omitempty"`
+
+	// Chunks is the cleaned content split for retrieval when the request
+	// enabled chunking.
+	Chunks []Chunk `json:"chunks,omitempty"`
+
+	// Truncated reports that max_tokens elided part of the content;
+	// TruncatedTokens is how many tokens were removed.
+	Truncated       bool `json:"truncated,omitempty"`
+	TruncatedTokens int  `json:"truncated_tokens,omitempty"`
+
+	// InteractiveElements lists the page's visible interactive elements
+	// when the request enabled interactive_elements.
+	InteractiveElements []InteractiveElement `json:"interactive_elements,omitempty"`
+
+	// Fingerprint is the SimHash of the cleaned content as 16 hex digits;
+	// set on batch and crawl results. Near-duplicate pages have
+	// fingerprints a few bits apart.
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	// DuplicateOf is the URL of an earlier crawled page this one is a
+	// near-duplicate of, when the crawl enabled dedupe.
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+
+	// Debug reports what the scraper did to the page besides loading it.
+	// Omitted when there is nothing to report.
+	Debug *DebugInfo `json:"debug,omitempty"`
+
 	// Error is populated only when Success is false.
 	Error *ErrorDetail `json:"error,omitempty"`
 }
 
+// PageContent is the cleaned output of a single page in a paginated scrape.
+type PageContent struct {
+	URL     string    `json:"url"`
+	Content string    `json:"content"`
+	Tokens  TokenInfo `json:"tokens"`
+}
+
+// Chunk is a piece of the cleaned content. Start and End are character
+// (Unicode code point) offsets into Content, and Text is Content[Start:End].
+type Chunk struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+
+	// HeadingPath lists the markdown headings the chunk falls under,
+	// outermost first (H1 > H2 > H3).
+	HeadingPath []string `json:"heading_path,omitempty"`
+
+	Start  int `json:"start"`
+	End    int `json:"end"`
+	Tokens int `json:"tokens"`
+}
+
+// InteractiveElement is a visible element an agent can act on. Ref can be
+// passed as Action.Ref to target it.
+type InteractiveElement struct {
+	Ref      int         `json:"ref"`
+	Role     string      `json:"role"`
+	Label    string      `json:"label,omitempty"`
+	Selector string      `json:"selector"`
+	BBox     BoundingBox `json:"bbox"`
+}
+
+// BoundingBox is an element's position in document coordinates (CSS pixels).
+type BoundingBox struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// DebugInfo reports scraper interventions during a browser session.
+type DebugInfo struct {
+	// BlockedRequests is the number of requests failed by resource, URL
+	// pattern and ad blocking.
+	BlockedRequests int `json:"blocked_requests,omitempty"`
+
+	// ConsentManager is the consent management platform answered by the
+	// consent option (e.g. "onetrust", "didomi", "tcf").
+	ConsentManager string `json:"consent_manager,omitempty"`
+}
+
+// DownloadedFile is a file downloaded during a scrape.
+type DownloadedFile struct {
+	Filename string `json:"filename"`
+	URL      string `json:"url,omitempty"`
+	MIMEType string `json:"mime_type,omitempty"`
+	Size     int64  `json:"size"`
+
+	// Data is the base64-encoded file. Omitted when the file exceeded
+	// downloads.max_size_mb or did not complete (see Error).
+	Data string `json:"data,omitempty"`
+
+	// Content is the extracted text when downloads.ingest is set and the
+	// file is HTML, text or PDF.
+	Content string `json:"content,omitempty"`
+
+	// Error explains why Data or Content is missing.
+	Error string `json:"error,omitempty"`
+}
+
 // LinksResult separates extracted links into internal and external groups.
 type LinksResult struct {
 	Internal []Link `json:"internal"`
@@ -89,8 +193,15 @@ type TokenInfo struct {
 	// CleanedEstimate is the estimated token count of the cleaned output.
 	CleanedEstimate int `json:"cleaned_estimate"`
 
-	// SavingsPercent is the percentage of tokens removed (0-100).
+	// SavingsPercent is the percentage of tokens removed (0-100), from the
+	// tokenizer counts when a tokenizer was chosen.
 	SavingsPercent float64 `json:"savings_percent"`
+
+	// Tokenizer names the BPE tokenizer behind OriginalCount and
+	// CleanedCount; empty when only the estimate was computed.
+	Tokenizer     string `json:"tokenizer,omitempty"`
+	OriginalCount int    `json:"original_count,omitempty"`
+	CleanedCount  int    `json:"cleaned_count,omitempty"`
 }
 
 // TimingInfo breaks down the time spent in each phase.
@@ -0,0 +1,288 @@
+// Package monitor re-scrapes registered URLs on a schedule and reports
+// significant changes to their cleaned content.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/use-agent/purify/config"
+	"github.com/use-agent/purify/models"
+	"github.com/use-agent/purify/simhash"
+	"github.com/use-agent/purify/webhook"
+)
+
+// CheckFunc scrapes a monitor's URL and returns its cleaned content.
+type CheckFunc func(ctx context.Context, m *models.Monitor) (string, error)
+
+// Scheduler holds the registered monitors and runs their checks. Safe for
+// concurrent use.
+type Scheduler struct {
+	check CheckFunc
+	cfg   config.MonitorConfig
+
+	mu       sync.Mutex
+	monitors map[string]*entry
+}
+
+// entry is a monitor with the content changes are compared against.
+type entry struct {
+	m          models.Monitor
+	running    bool
+	baseline   bool // content and fp are set
+	content    string
+	fp         uint64
+	baselineAt time.Time
+}
+
+// NewScheduler returns an empty scheduler that checks monitors with check.
+func NewScheduler(check CheckFunc, cfg config.MonitorConfig) *Scheduler {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	return &Scheduler{
+		check:    check,
+		cfg:      cfg,
+		monitors: make(map[string]*entry),
+	}
+}
+
+// Add registers m, due for its first check now. It returns false when m's
+// owner already has MaxPerKey monitors.
+func (s *Scheduler) Add(m *models.Monitor) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cfg.MaxPerKey > 0 && s.count(m.Owner) >= s.cfg.MaxPerKey {
+		return false
+	}
+	m.NextCheckAt = time.Now().Unix()
+	s.monitors[m.ID] = &entry{m: *m}
+	return true
+}
+
+// Get returns a copy of the monitor with the given ID.
+func (s *Scheduler) Get(id string) (models.Monitor, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.monitors[id]
+	if !ok {
+		return models.Monitor{}, false
+	}
+	return e.m, true
+}
+
+// List returns copies of owner's monitors, oldest first.
+func (s *Scheduler) List(owner string) []models.Monitor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []models.Monitor{}
+	for _, e := range s.monitors {
+		if e.m.Owner == owner {
+			out = append(out, e.m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt < out[j].CreatedAt
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// Remove deletes a monitor. It reports whether the monitor existed.
+func (s *Scheduler) Remove(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.monitors[id]
+	delete(s.monitors, id)
+	return ok
+}
+
+func (s *Scheduler) count(owner string) int {
+	n := 0
+	for _, e := range s.monitors {
+		if e.m.Owner == owner {
+			n++
+		}
+	}
+	return n
+}
+
+// Run starts due checks, at most Concurrency at a time, until ctx is done.
+// It returns once the checks in flight, which see ctx canceled, finish.
+func (s *Scheduler) Run(ctx context.Context) {
+	sem := make(chan struct{}, s.cfg.Concurrency)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, m := range s.due() {
+			select {
+			case sem <- struct{}{}:
+			default:
+				// All workers busy; the rest stay due for the next tick.
+				s.release(m.ID)
+				continue
+			}
+			wg.Add(1)
+			go func(m models.Monitor) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				s.runCheck(ctx, m)
+			}(m)
+		}
+	}
+}
+
+// due marks the monitors whose check is due as running and returns copies
+// of them, the most overdue first.
+func (s *Scheduler) due() []models.Monitor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().Unix()
+	var out []models.Monitor
+	for _, e := range s.monitors {
+		if !e.running && e.m.NextCheckAt <= now {
+			e.running = true
+			out = append(out, e.m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NextCheckAt < out[j].NextCheckAt })
+	return out
+}
+
+// release clears the running mark of a monitor that was not checked.
+func (s *Scheduler) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.monitors[id]; ok {
+		e.running = false
+	}
+}
+
+// runCheck scrapes one monitor and records the result.
+func (s *Scheduler) runCheck(ctx context.Context, m models.Monitor) {
+	content, err := s.check(ctx, &m)
+	now := time.Now()
+
+	s.mu.Lock()
+	e, ok := s.monitors[m.ID]
+	if !ok {
+		// Removed while the check ran.
+		s.mu.Unlock()
+		return
+	}
+	e.running = false
+	change := e.record(content, err, now)
+	snapshot := e.m
+	s.mu.Unlock()
+
+	if err != nil {
+		slog.Warn("monitor check failed", "id", m.ID, "url", m.URL, "error", err)
+		return
+	}
+	if change == nil {
+		return
+	}
+	slog.Info("monitor change detected",
+		"id", m.ID,
+		"url", m.URL,
+		"distance", change.Distance,
+		"added", change.Added,
+		"removed", change.Removed,
+	)
+	if snapshot.WebhookURL != "" {
+		webhook.DeliverAsync(snapshot.WebhookURL, snapshot.WebhookSecret, &webhook.Event{
+			Type:      "monitor.changed",
+			JobID:     snapshot.ID,
+			Timestamp: now.Unix(),
+			Data:      change,
+		})
+	}
+}
+
+// record updates the monitor with the result of a check and returns the
+// change to report, if any. The first successful check sets the baseline;
+// a reported change replaces it, while smaller changes keep comparing
+// against the old baseline so slow drift is still caught.
+func (e *entry) record(content string, err error, now time.Time) *models.MonitorChange {
+	e.m.Checks++
+	e.m.LastCheckedAt = now.Unix()
+	e.m.NextCheckAt = now.Add(time.Duration(e.m.Interval) * time.Second).Unix()
+	if err != nil {
+		scrapeErr, ok := err.(*models.ScrapeError)
+		if !ok {
+			scrapeErr = models.NewScrapeError(models.ErrCodeInternal, err.Error(), err)
+		}
+		e.m.LastError = scrapeErr.ToDetail()
+		return nil
+	}
+	e.m.LastError = nil
+
+	fp := simhash.FingerprintWith(content, simhash.Options{})
+	if !e.baseline {
+		e.setBaseline(content, fp, now)
+		return nil
+	}
+	if content == e.content {
+		return nil
+	}
+	distance := simhash.Distance(e.fp, fp)
+	if e.m.Threshold > 0 && distance <= e.m.Threshold {
+		return nil
+	}
+
+	diff, added, removed := UnifiedDiff(e.content, content,
+		diffLabel(e.m.URL, e.baselineAt), diffLabel(e.m.URL, now))
+	change := &models.MonitorChange{
+		MonitorID:           e.m.ID,
+		URL:                 e.m.URL,
+		CheckedAt:           now.Unix(),
+		PreviousFingerprint: e.m.Fingerprint,
+		Fingerprint:         fingerprintHex(fp),
+		Distance:            distance,
+		Added:               added,
+		Removed:             removed,
+		Diff:                diff,
+	}
+	e.m.Changes++
+	e.m.LastChangedAt = now.Unix()
+	e.m.LastChange = change
+	e.setBaseline(content, fp, now)
+	return change
+}
+
+func (e *entry) setBaseline(content string, fp uint64, at time.Time) {
+	e.baseline = true
+	e.content = content
+	e.fp = fp
+	e.baselineAt = at
+	e.m.Fingerprint = fingerprintHex(fp)
+}
+
+// diffLabel names one side of a diff like diff -u does: the URL and the
+// time the content was scraped.
+func diffLabel(url string, at time.Time) string {
+	return url + "\t" + at.UTC().Format(time.RFC3339)
+}
+
+// fingerprintHex formats a fingerprint as 16 hex digits, or "" for the
+// fingerprint of empty content.
+func fingerprintHex(fp uint64) string {
+	if fp == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%016x", fp)
+}
@@ -0,0 +1,224 @@
+package scraper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// axPrunedRoles are structural roles without meaning for an agent; their
+// children are rendered in their place.
+var axPrunedRoles = map[string]bool{
+	"generic":         true,
+	"none":            true,
+	"presentation":    true,
+	"InlineTextBox":   true,
+	"LineBreak":       true,
+	"LayoutTable":     true,
+	"LayoutTableRow":  true,
+	"LayoutTableCell": true,
+}
+
+// axStates are the properties rendered as node state, in output order.
+var axStates = []proto.AccessibilityAXPropertyName{
+	proto.AccessibilityAXPropertyNameLevel,
+	proto.AccessibilityAXPropertyNameChecked,
+	proto.AccessibilityAXPropertyNamePressed,
+	proto.AccessibilityAXPropertyNameSelected,
+	proto.AccessibilityAXPropertyNameExpanded,
+	proto.AccessibilityAXPropertyNameDisabled,
+	proto.AccessibilityAXPropertyNameRequired,
+	proto.AccessibilityAXPropertyNameReadonly,
+	proto.AccessibilityAXPropertyNameInvalid,
+	proto.AccessibilityAXPropertyNameFocused,
+	proto.AccessibilityAXPropertyNameModal,
+}
+
+// accessibilitySnapshot returns the page's accessibility tree as an
+// indented outline (see renderAXTree).
+func accessibilitySnapshot(p *rod.Page) (string, error) {
+	res, err := proto.AccessibilityGetFullAXTree{}.Call(p)
+	if err != nil {
+		return "", err
+	}
+	return renderAXTree(res.Nodes), nil
+}
+
+// renderAXTree renders accessibility nodes as an indented outline with one
+// `- role "name" value="..." [states]` line per node, e.g.
+// `- textbox "Email" value="a@b.c" [required, focused]`. A node whose only
+// content is text is written inline as `- paragraph: Plans start at $9.`.
+//
+// Ignored nodes and structural roles (generic, presentation, layout tables)
+// are pruned with their children moved up, and text that only repeats the
+// name of its parent is dropped.
+func renderAXTree(nodes []*proto.AccessibilityAXNode) string {
+	if len(nodes) == 0 {
+		return ""
+	}
+	byID := make(map[proto.AccessibilityAXNodeID]*proto.AccessibilityAXNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.NodeID] = n
+	}
+	var root *proto.AccessibilityAXNode
+	for _, n := range nodes {
+		if _, ok := byID[n.ParentID]; !ok {
+			root = n
+			break
+		}
+	}
+	if root == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	var render func(n *proto.AccessibilityAXNode, depth int, parentName string)
+	children := func(n *proto.AccessibilityAXNode, depth int, parentName string) {
+		for _, id := range n.ChildIDs {
+			if c, ok := byID[id]; ok {
+				render(c, depth, parentName)
+			}
+		}
+	}
+	render = func(n *proto.AccessibilityAXNode, depth int, parentName string) {
+		role := axString(n.Role)
+		if n.Ignored || axPrunedRoles[role] {
+			children(n, depth, parentName)
+			return
+		}
+		name := strings.TrimSpace(axString(n.Name))
+
+		if role == "StaticText" {
+			if name != "" && !strings.Contains(parentName, name) {
+				writeAXLine(&b, depth, "text", "", name)
+			}
+			return
+		}
+
+		line := role
+		if name != "" {
+			line += " " + strconv.Quote(name)
+		}
+		if v := strings.TrimSpace(axString(n.Value)); v != "" {
+			line += " value=" + strconv.Quote(v)
+		}
+		if states := axNodeStates(n); len(states) > 0 {
+			line += " [" + strings.Join(states, ", ") + "]"
+		}
+
+		// A node whose only content is a line of text is written inline.
+		if name == "" {
+			if text, ok := axSingleText(n, byID); ok {
+				writeAXLine(&b, depth, line, ":", text)
+				return
+			}
+		}
+		writeAXLine(&b, depth, line, "", "")
+		children(n, depth+1, name)
+	}
+	render(root, 0, "")
+	return b.String()
+}
+
+// writeAXLine writes one outline entry: head, then either `sep text` or a
+// quoted text for bare text nodes.
+func writeAXLine(b *strings.Builder, depth int, head, sep, text string) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString("- ")
+	b.WriteString(head)
+	switch {
+	case sep != "":
+		b.WriteString(sep + " " + text)
+	case text != "":
+		b.WriteString(" " + strconv.Quote(text))
+	}
+	b.WriteByte('\n')
+}
+
+// axSingleText returns the text of a node whose rendered content would be a
+// single text line (static text, possibly nested in pruned nodes).
+func axSingleText(n *proto.AccessibilityAXNode, byID map[proto.AccessibilityAXNodeID]*proto.AccessibilityAXNode) (string, bool) {
+	var texts []string
+	var collect func(*proto.AccessibilityAXNode) bool
+	collect = func(n *proto.AccessibilityAXNode) bool {
+		for _, id := range n.ChildIDs {
+			c, ok := byID[id]
+			if !ok {
+				continue
+			}
+			role := axString(c.Role)
+			switch {
+			case c.Ignored || axPrunedRoles[role]:
+				if !collect(c) {
+					return false
+				}
+			case role == "StaticText":
+				if t := strings.TrimSpace(axString(c.Name)); t != "" {
+					texts = append(texts, t)
+				}
+			default:
+				return false
+			}
+		}
+		return true
+	}
+	if !collect(n) || len(texts) == 0 {
+		return "", false
+	}
+	return strings.Join(texts, " "), true
+}
+
+// axNodeStates formats the node's state properties, e.g. "level=2",
+// "checked", "expanded=false".
+func axNodeStates(n *proto.AccessibilityAXNode) []string {
+	props := make(map[proto.AccessibilityAXPropertyName]*proto.AccessibilityAXValue, len(n.Properties))
+	for _, p := range n.Properties {
+		props[p.Name] = p.Value
+	}
+	var states []string
+	for _, name := range axStates {
+		v, ok := props[name]
+		if !ok || v == nil {
+			continue
+		}
+		s := axString(v)
+		switch name {
+		case proto.AccessibilityAXPropertyNameLevel:
+			states = append(states, "level="+s)
+		case proto.AccessibilityAXPropertyNameExpanded:
+			states = append(states, "expanded="+s)
+		case proto.AccessibilityAXPropertyNameInvalid:
+			if s != "" && s != "false" {
+				states = append(states, "invalid")
+			}
+		default:
+			switch s {
+			case "true":
+				states = append(states, string(name))
+			case "mixed":
+				states = append(states, string(name)+"=mixed")
+			}
+		}
+	}
+	return states
+}
+
+// axString returns an AX value as text ("" for nil or empty values).
+func axString(v *proto.AccessibilityAXValue) string {
+	if v == nil || v.Value.Nil() {
+		return ""
+	}
+	switch val := v.Value.Val().(type) {
+	case string:
+		return val
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
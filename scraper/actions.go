@@ -3,9 +3,12 @@ package scraper
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/use-agent/purify/models"
 )
@@ -36,6 +39,10 @@ func executeSingleAction(ctx context.Context, page *rod.Page, action models.Acti
 
 	p := page.Context(actionCtx)
 
+	if action.Ref > 0 {
+		action = targetRef(p, action)
+	}
+
 	switch action.Type {
 	case "wait":
 		return execWait(p, action)
@@ -49,16 +56,47 @@ func executeSingleAction(ctx context.Context, page *rod.Page, action models.Acti
 		// "scrape" is a no-op marker for multi-step scraping; the caller
 		// handles capturing page state. For now we just succeed.
 		return nil
+	case "navigate":
+		return execNavigate(p, action)
+	case "type":
+		return execType(p, action)
+	case "press":
+		return execPress(p, action)
+	case "hover":
+		return execHover(p, action)
+	case "set_viewport":
+		return execSetViewport(p, action)
 	default:
 		return fmt.Errorf("unknown action type: %s", action.Type)
 	}
 }
 
-// execWait either sleeps for a duration or waits for a CSS selector to appear.
+// targetRef rewrites an action that targets an interactive-element ref to
+// use the ref's selector, keeping any explicit selectors as fallbacks. Refs
+// are assigned on first use when the page has not been captured yet.
+func targetRef(p *rod.Page, action models.Action) models.Action {
+	sel := refSelector(action.Ref)
+	if has, _, _ := p.Has(sel); !has {
+		interactiveElements(p)
+	}
+	fallbacks := action.FallbackSelectors
+	if action.Selector != "" {
+		fallbacks = append([]string{action.Selector}, fallbacks...)
+	}
+	action.Selector = sel
+	action.FallbackSelectors = fallbacks
+	return action
+}
+
+// execWait sleeps for a duration, waits for a selector to appear, or waits
+// for a JavaScript expression to become truthy.
 func execWait(p *rod.Page, action models.Action) error {
 	if action.Selector != "" {
 		// Wait for at least one element matching the selector to appear.
-		return p.WaitElementsMoreThan(action.Selector, 0)
+		return waitForSelector(p, action.Selector)
+	}
+	if action.Expression != "" {
+		return p.Wait(rod.Eval(`(expr) => !!(0, eval)(expr)`, action.Expression))
 	}
 	if action.Milliseconds > 0 {
 		d := time.Duration(action.Milliseconds) * time.Millisecond
@@ -77,11 +115,173 @@ func execClick(p *rod.Page, action models.Action) error {
 	if action.Selector == "" {
 		return fmt.Errorf("click action requires a selector")
 	}
-	el, err := p.Element(action.Selector)
+	el, err := findElement(p, actionSelectors(action)...)
 	if err != nil {
 		return fmt.Errorf("element %q not found: %w", action.Selector, err)
 	}
-	return el.Click(proto.InputMouseButtonLeft, 1)
+	clicks := action.ClickCount
+	if clicks <= 0 {
+		clicks = 1
+	}
+	return el.Click(proto.InputMouseButtonLeft, clicks)
+}
+
+// hasActionType reports whether any action has the given type.
+func hasActionType(actions []models.Action, typ string) bool {
+	for _, a := range actions {
+		if a.Type == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// actionSelectors returns the action's selector followed by its fallbacks.
+func actionSelectors(action models.Action) []string {
+	return append([]string{action.Selector}, action.FallbackSelectors...)
+}
+
+// execNavigate loads a new URL in the current tab.
+func execNavigate(p *rod.Page, action models.Action) error {
+	if action.URL == "" {
+		return fmt.Errorf("navigate action requires a url")
+	}
+	if err := p.Navigate(action.URL); err != nil {
+		return err
+	}
+	_ = p.WaitDOMStable(300*time.Millisecond, 0.1)
+	return nil
+}
+
+// execType replaces the value of a form field. Text inputs receive real key
+// events; <select> elements get their value set and change events fired.
+func execType(p *rod.Page, action models.Action) error {
+	if action.Selector == "" {
+		return fmt.Errorf("type action requires a selector")
+	}
+	el, err := findElement(p, actionSelectors(action)...)
+	if err != nil {
+		return fmt.Errorf("element %q not found: %w", action.Selector, err)
+	}
+
+	tag, err := el.Eval(`() => this.tagName`)
+	if err != nil {
+		return err
+	}
+	if tag.Value.Str() == "SELECT" {
+		_, err = el.Eval(`(v) => {
+			this.value = v;
+			this.dispatchEvent(new Event('input', {bubbles: true}));
+			this.dispatchEvent(new Event('change', {bubbles: true}));
+		}`, action.Text)
+		return err
+	}
+
+	if err := el.SelectAllText(); err != nil {
+		slog.Debug("type: select-all failed, appending instead", "error", err)
+	}
+	return el.Input(action.Text)
+}
+
+// execPress presses a key, optionally with modifiers ("Control+a").
+func execPress(p *rod.Page, action models.Action) error {
+	if action.Key == "" {
+		return fmt.Errorf("press action requires a key")
+	}
+	keys, err := parseKeyCombo(action.Key)
+	if err != nil {
+		return err
+	}
+	ka := p.KeyActions()
+	for _, mod := range keys[:len(keys)-1] {
+		ka = ka.Press(mod)
+	}
+	return ka.Type(keys[len(keys)-1]).Do()
+}
+
+// execHover moves the mouse over the element matching the selector.
+func execHover(p *rod.Page, action models.Action) error {
+	if action.Selector == "" {
+		return fmt.Errorf("hover action requires a selector")
+	}
+	el, err := findElement(p, actionSelectors(action)...)
+	if err != nil {
+		return fmt.Errorf("element %q not found: %w", action.Selector, err)
+	}
+	return el.Hover()
+}
+
+// execSetViewport emulates a screen size. The override is cleared when the
+// page returns to the pool.
+func execSetViewport(p *rod.Page, action models.Action) error {
+	v := action.Viewport
+	if v == nil {
+		return fmt.Errorf("set_viewport action requires a viewport")
+	}
+	scale := v.DeviceScaleFactor
+	if scale <= 0 {
+		scale = 1
+	}
+	if err := (proto.EmulationSetDeviceMetricsOverride{
+		Width:             v.Width,
+		Height:            v.Height,
+		DeviceScaleFactor: scale,
+		Mobile:            v.Mobile,
+	}).Call(p); err != nil {
+		return err
+	}
+	return proto.EmulationSetTouchEmulationEnabled{Enabled: v.HasTouch}.Call(p)
+}
+
+// namedKeys maps DOM KeyboardEvent.key names to rod keys.
+var namedKeys = map[string]input.Key{
+	"Enter":      input.Enter,
+	"Tab":        input.Tab,
+	"Escape":     input.Escape,
+	"Backspace":  input.Backspace,
+	"Delete":     input.Delete,
+	"Space":      input.Space,
+	"ArrowUp":    input.ArrowUp,
+	"ArrowDown":  input.ArrowDown,
+	"ArrowLeft":  input.ArrowLeft,
+	"ArrowRight": input.ArrowRight,
+	"Home":       input.Home,
+	"End":        input.End,
+	"PageUp":     input.PageUp,
+	"PageDown":   input.PageDown,
+	"Shift":      input.ShiftLeft,
+	"Control":    input.ControlLeft,
+	"Alt":        input.AltLeft,
+	"Meta":       input.MetaLeft,
+}
+
+// parseKeyCombo parses "Enter", "a" or "Control+Shift+k" into rod keys;
+// the last key is the one typed, the others are held as modifiers.
+func parseKeyCombo(combo string) ([]input.Key, error) {
+	parts := strings.Split(combo, "+")
+	if strings.HasSuffix(combo, "++") || combo == "+" {
+		// "Control++" means Control plus the "+" key.
+		parts = append(strings.Split(strings.TrimSuffix(combo, "++"), "+"), "+")
+	}
+	keys := make([]input.Key, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if k, ok := namedKeys[part]; ok {
+			keys = append(keys, k)
+			continue
+		}
+		if r := []rune(part); len(r) == 1 && r[0] >= ' ' && r[0] <= '~' {
+			keys = append(keys, input.Key(r[0]))
+			continue
+		}
+		return nil, fmt.Errorf("unsupported key %q", part)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("invalid key %q", combo)
+	}
+	return keys, nil
 }
 
 // execScroll scrolls the page up or down by the specified number of viewports.
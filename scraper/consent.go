@@ -0,0 +1,129 @@
+package scraper
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// consentWait is how long to wait for a consent manager to load. Most CMPs
+// inject their banner shortly after DOMContentLoaded.
+const consentWait = 2 * time.Second
+
+// consentJS detects a known consent management platform and answers it with
+// "accept" or "reject", preferring the CMP's own JS API over clicking its
+// buttons. Returns the CMP name, or "" when none was found or could be
+// answered.
+const consentJS = `(mode) => {
+	const accept = mode === 'accept';
+	const visible = (el) => !!el && el.getClientRects().length > 0;
+	const click = (sel) => {
+		const el = document.querySelector(sel);
+		if (!visible(el)) return false;
+		el.click();
+		return true;
+	};
+	const words = accept
+		? ['accept all', 'allow all', 'agree to all', 'accept', 'agree', 'i agree', 'allow', 'ok']
+		: ['reject all', 'decline all', 'refuse all', 'deny all', 'reject', 'decline', 'refuse', 'deny', 'disagree', 'continue without accepting'];
+	const clickText = (root) => {
+		if (!root) return false;
+		const buttons = root.querySelectorAll('button, [role="button"], a, input[type="button"], input[type="submit"]');
+		for (const w of words) {
+			for (const b of buttons) {
+				const text = (b.innerText || b.value || '').trim().toLowerCase();
+				if (text === w && visible(b)) {
+					b.click();
+					return true;
+				}
+			}
+		}
+		return false;
+	};
+
+	// OneTrust
+	try {
+		if (window.OneTrust && (accept ? OneTrust.AllowAll : OneTrust.RejectAll)) {
+			accept ? OneTrust.AllowAll() : OneTrust.RejectAll();
+			return 'onetrust';
+		}
+		if (click(accept ? '#onetrust-accept-btn-handler' : '#onetrust-reject-all-handler')) return 'onetrust';
+	} catch (e) {}
+
+	// Didomi
+	try {
+		if (window.Didomi && window.Didomi.setUserAgreeToAll) {
+			accept ? Didomi.setUserAgreeToAll() : Didomi.setUserDisagreeToAll();
+			return 'didomi';
+		}
+		if (click(accept ? '#didomi-notice-agree-button' : '#didomi-notice-disagree-button')) return 'didomi';
+	} catch (e) {}
+
+	// Usercentrics (v2 UC_UI, v3 __ucCmp)
+	try {
+		if (window.UC_UI && window.UC_UI.acceptAllConsents) {
+			const done = accept ? UC_UI.acceptAllConsents() : UC_UI.denyAllConsents();
+			Promise.resolve(done).then(() => UC_UI.closeCMP && UC_UI.closeCMP());
+			return 'usercentrics';
+		}
+		if (window.__ucCmp && window.__ucCmp.acceptAllConsents) {
+			const done = accept ? __ucCmp.acceptAllConsents() : __ucCmp.denyAllConsents();
+			Promise.resolve(done).then(() => __ucCmp.closeCmp && __ucCmp.closeCmp());
+			return 'usercentrics';
+		}
+	} catch (e) {}
+
+	// Quantcast Choice
+	try {
+		const qc = document.querySelector('#qc-cmp2-ui, .qc-cmp2-container');
+		if (qc && clickText(qc)) return 'quantcast';
+		if (qc && click(accept ? '#qc-cmp2-ui button[mode="primary"]' : '#qc-cmp2-ui button[mode="secondary"]')) return 'quantcast';
+	} catch (e) {}
+
+	// TrustArc
+	try {
+		if (click(accept ? '#truste-consent-button' : '#truste-consent-required')) return 'trustarc';
+		const ta = document.querySelector('#truste-consent-track, #consent_blackbar');
+		if (ta && clickText(ta)) return 'trustarc';
+	} catch (e) {}
+
+	// Any other IAB TCF CMP: answer the banner by its button labels.
+	try {
+		if (typeof window.__tcfapi === 'function') {
+			const banners = document.querySelectorAll('[role="dialog"], [id*="consent" i], [class*="consent" i], [id*="cmp" i], [class*="cmp" i], [id*="cookie" i], [class*="cookie" i], [id*="gdpr" i], [class*="gdpr" i]');
+			for (const b of banners) {
+				if (visible(b) && clickText(b)) return 'tcf';
+			}
+		}
+	} catch (e) {}
+
+	return '';
+}`
+
+// handleConsent answers the page's cookie-consent banner with mode
+// ("accept" or "reject") through a known CMP handler, polling up to
+// consentWait for one to load. Returns the name of the CMP handled, or ""
+// when none was found.
+func handleConsent(ctx context.Context, p *rod.Page, mode string) string {
+	deadline := time.Now().Add(consentWait)
+	for {
+		if res, err := p.Eval(consentJS, mode); err == nil {
+			if cmp := res.Value.Str(); cmp != "" {
+				slog.Debug("consent: answered CMP", "cmp", cmp, "mode", mode)
+				// Give gated content a moment to load after consent.
+				_ = p.WaitDOMStable(300*time.Millisecond, 0.1)
+				return cmp
+			}
+		}
+		if time.Now().After(deadline) {
+			return ""
+		}
+		select {
+		case <-ctx.Done():
+			return ""
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package scraper
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// downloadStartGrace is how long to wait for a download to begin after the
+// last action when none has started yet (a click may trigger it slightly
+// after returning).
+const downloadStartGrace = 1500 * time.Millisecond
+
+// downloadSession is a scrape running in its own incognito browser context
+// with downloads enabled. A separate context keeps the download directory
+// and behavior from leaking into pool pages used by concurrent requests.
+type downloadSession struct {
+	browser *rod.Browser // incognito context
+	page    *rod.Page
+	dir     string
+	stop    context.CancelFunc
+
+	mu        sync.Mutex
+	downloads map[string]*trackedDownload
+	order     []string
+}
+
+// trackedDownload is the state of one download, keyed by its GUID.
+type trackedDownload struct {
+	url      string
+	filename string
+	state    proto.BrowserDownloadProgressState
+}
+
+// openDownloadSession creates an incognito context with a fresh download
+// directory and a page inside it. The caller must call close.
+func (s *Scraper) openDownloadSession() (*downloadSession, error) {
+	incognito, err := s.browser.Incognito()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "purify-downloads-")
+	if err != nil {
+		_ = incognito.Close()
+		return nil, err
+	}
+	err = proto.BrowserSetDownloadBehavior{
+		Behavior:         proto.BrowserSetDownloadBehaviorBehaviorAllowAndName,
+		BrowserContextID: incognito.BrowserContextID,
+		DownloadPath:     dir,
+		EventsEnabled:    true,
+	}.Call(incognito)
+	if err != nil {
+		_ = incognito.Close()
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	page, err := incognito.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		_ = incognito.Close()
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ds := &downloadSession{
+		browser:   incognito,
+		page:      page,
+		dir:       dir,
+		stop:      cancel,
+		downloads: make(map[string]*trackedDownload),
+	}
+	go incognito.Context(ctx).EachEvent(
+		func(e *proto.BrowserDownloadWillBegin) {
+			// Download events are browser-wide; keep only those started by
+			// a frame of this session's page.
+			if !ds.ownsFrame(e.FrameID) {
+				return
+			}
+			ds.mu.Lock()
+			ds.downloads[e.GUID] = &trackedDownload{
+				url:      e.URL,
+				filename: e.SuggestedFilename,
+				state:    proto.BrowserDownloadProgressStateInProgress,
+			}
+			ds.order = append(ds.order, e.GUID)
+			ds.mu.Unlock()
+		},
+		func(e *proto.BrowserDownloadProgress) {
+			ds.mu.Lock()
+			if d, ok := ds.downloads[e.GUID]; ok {
+				d.state = e.State
+			}
+			ds.mu.Unlock()
+		},
+	)()
+	return ds, nil
+}
+
+// ownsFrame reports whether frameID belongs to the session's page.
+func (ds *downloadSession) ownsFrame(frameID proto.PageFrameID) bool {
+	if frameID == ds.page.FrameID {
+		return true
+	}
+	tree, err := proto.PageGetFrameTree{}.Call(ds.page)
+	if err != nil {
+		return false
+	}
+	var walk func(*proto.PageFrameTree) bool
+	walk = func(t *proto.PageFrameTree) bool {
+		if t.Frame.ID == frameID {
+			return true
+		}
+		for _, child := range t.ChildFrames {
+			if walk(child) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(tree.FrameTree)
+}
+
+// wait blocks until every started download has finished, or ctx expires.
+// If nothing has started yet, it waits up to downloadStartGrace for one.
+func (ds *downloadSession) wait(ctx context.Context) {
+	start := time.Now()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		ds.mu.Lock()
+		started, pending := len(ds.order), 0
+		for _, d := range ds.downloads {
+			if d.state == proto.BrowserDownloadProgressStateInProgress {
+				pending++
+			}
+		}
+		ds.mu.Unlock()
+
+		if started > 0 && pending == 0 {
+			return
+		}
+		if started == 0 && time.Since(start) >= downloadStartGrace {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// results reads the downloaded files, capping each at maxBytes.
+func (ds *downloadSession) results(maxBytes int64) []DownloadResult {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	out := make([]DownloadResult, 0, len(ds.order))
+	for _, guid := range ds.order {
+		d := ds.downloads[guid]
+		r := DownloadResult{Filename: d.filename, URL: d.url}
+		if d.state != proto.BrowserDownloadProgressStateCompleted {
+			r.Error = "download " + string(d.state)
+			out = append(out, r)
+			continue
+		}
+
+		path := filepath.Join(ds.dir, guid)
+		info, err := os.Stat(path)
+		if err != nil {
+			r.Error = "downloaded file not found"
+			out = append(out, r)
+			continue
+		}
+		r.Size = info.Size()
+		if r.Size > maxBytes {
+			r.Error = "file exceeds the download size cap"
+			r.MIMEType = mime.TypeByExtension(filepath.Ext(d.filename))
+			out = append(out, r)
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			r.Error = err.Error()
+			out = append(out, r)
+			continue
+		}
+		r.Data, err = io.ReadAll(io.LimitReader(f, maxBytes))
+		f.Close()
+		if err != nil {
+			r.Error = err.Error()
+			r.Data = nil
+		}
+		r.MIMEType = detectDownloadType(d.filename, r.Data)
+		out = append(out, r)
+	}
+	return out
+}
+
+// close disposes the incognito context (closing its tabs) and deletes the
+// download directory.
+func (ds *downloadSession) close() {
+	ds.stop()
+	if err := ds.browser.Close(); err != nil {
+		slog.Warn("downloads: failed to dispose browser context", "error", err)
+	}
+	if err := os.RemoveAll(ds.dir); err != nil {
+		slog.Warn("downloads: failed to remove download dir", "dir", ds.dir, "error", err)
+	}
+}
+
+// detectDownloadType prefers the file extension and falls back to content
+// sniffing.
+func detectDownloadType(filename string, data []byte) string {
+	if t := mime.TypeByExtension(filepath.Ext(filename)); t != "" {
+		return t
+	}
+	return http.DetectContentType(data)
+}
@@ -0,0 +1,164 @@
+package scraper
+
+import (
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/use-agent/purify/engine"
+	"github.com/use-agent/purify/models"
+)
+
+// emulationFor collects the request's emulation options, or returns nil
+// when none are set.
+func emulationFor(req *models.ScrapeRequest) *engine.Emulation {
+	if req.Device == "" && req.Locale == "" && req.TimezoneID == "" && req.Geolocation == nil {
+		return nil
+	}
+	em := &engine.Emulation{
+		Device:     req.Device,
+		Locale:     req.Locale,
+		TimezoneID: req.TimezoneID,
+	}
+	if g := req.Geolocation; g != nil {
+		em.Geolocation = &engine.Geolocation{Latitude: g.Latitude, Longitude: g.Longitude, Accuracy: g.Accuracy}
+	}
+	return em
+}
+
+// applyEmulation sets the device metrics, user agent, locale, timezone and
+// geolocation overrides on a page before navigation. pageURL is the origin
+// granted the geolocation permission.
+func applyEmulation(browser *rod.Browser, page *rod.Page, em *engine.Emulation, pageURL string) error {
+	device, hasDevice := engine.Devices[em.Device]
+	if hasDevice {
+		err := proto.EmulationSetDeviceMetricsOverride{
+			Width:             device.Width,
+			Height:            device.Height,
+			DeviceScaleFactor: device.DeviceScaleFactor,
+			Mobile:            device.Mobile,
+		}.Call(page)
+		if err != nil {
+			return err
+		}
+		maxTouch := 0
+		if device.HasTouch {
+			maxTouch = 5
+		}
+		err = proto.EmulationSetTouchEmulationEnabled{Enabled: device.HasTouch, MaxTouchPoints: &maxTouch}.Call(page)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The user agent override also carries Accept-Language and
+	// navigator.languages, so it is needed for a locale alone too.
+	if hasDevice || em.Locale != "" {
+		ua := proto.NetworkSetUserAgentOverride{}
+		if hasDevice {
+			ua.UserAgent = device.UserAgent
+			ua.Platform = device.Platform
+		} else if v, err := (proto.BrowserGetVersion{}).Call(browser); err == nil {
+			ua.UserAgent = strings.Replace(v.UserAgent, "HeadlessChrome", "Chrome", 1)
+		}
+		if em.Locale != "" {
+			ua.AcceptLanguage = engine.AcceptLanguage(em.Locale)
+		}
+		if err := ua.Call(page); err != nil {
+			return err
+		}
+	}
+
+	if em.Locale != "" {
+		if err := (proto.EmulationSetLocaleOverride{Locale: em.Locale}).Call(page); err != nil {
+			return models.NewScrapeError(models.ErrCodeInvalidInput, "unsupported locale: "+em.Locale, err)
+		}
+	}
+	if em.TimezoneID != "" {
+		if err := (proto.EmulationSetTimezoneOverride{TimezoneID: em.TimezoneID}).Call(page); err != nil {
+			return models.NewScrapeError(models.ErrCodeInvalidInput, "invalid timezone_id: "+em.TimezoneID, err)
+		}
+	}
+
+	if g := em.Geolocation; g != nil {
+		err := proto.EmulationSetGeolocationOverride{
+			Latitude:  &g.Latitude,
+			Longitude: &g.Longitude,
+			Accuracy:  &g.Accuracy,
+		}.Call(page)
+		if err != nil {
+			return err
+		}
+		if origin := originOf(pageURL); origin != "" {
+			_ = proto.BrowserGrantPermissions{
+				Permissions:      []proto.BrowserPermissionType{proto.BrowserPermissionTypeGeolocation},
+				Origin:           origin,
+				BrowserContextID: browser.BrowserContextID,
+			}.Call(browser)
+		}
+	}
+	return nil
+}
+
+// resetEmulation undoes the overrides set by applyEmulation and by
+// set_viewport actions before a page returns to the pool or to the caller's
+// own browser. Pooled pages never carry a geolocation grant: those requests
+// run in their own browser context (see openIsolatedPage).
+func resetEmulation(browser *rod.Browser, page *rod.Page, req *models.ScrapeRequest, em *engine.Emulation) {
+	if hasActionType(req.Actions, "set_viewport") || (em != nil && em.Device != "") {
+		_ = proto.EmulationClearDeviceMetricsOverride{}.Call(page)
+		_ = proto.EmulationSetTouchEmulationEnabled{Enabled: false}.Call(page)
+	}
+	if em == nil {
+		return
+	}
+	if em.Device != "" || em.Locale != "" {
+		_ = proto.NetworkSetUserAgentOverride{}.Call(page)
+	}
+	if em.Locale != "" {
+		_ = proto.EmulationSetLocaleOverride{}.Call(page)
+	}
+	if em.TimezoneID != "" {
+		_ = proto.EmulationSetTimezoneOverride{}.Call(page)
+	}
+	if em.Geolocation != nil {
+		_ = proto.EmulationClearGeolocationOverride{}.Call(page)
+		if origin := originOf(req.URL); origin != "" {
+			err := proto.BrowserSetPermission{
+				Permission:       &proto.BrowserPermissionDescriptor{Name: "geolocation"},
+				Setting:          proto.BrowserPermissionSettingPrompt,
+				Origin:           origin,
+				BrowserContextID: browser.BrowserContextID,
+			}.Call(browser)
+			if err != nil {
+				slog.Debug("emulation: failed to reset geolocation permission", "error", err)
+			}
+		}
+	}
+}
+
+// originOf returns the scheme://host[:port] origin of rawURL, or "".
+func originOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// openIsolatedPage creates an incognito browser context with one page.
+// Closing the returned context disposes the page.
+func (s *Scraper) openIsolatedPage() (*rod.Browser, *rod.Page, error) {
+	incognito, err := s.browser.Incognito()
+	if err != nil {
+		return nil, nil, err
+	}
+	page, err := incognito.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		_ = incognito.Close()
+		return nil, nil, err
+	}
+	return incognito, page, nil
+}
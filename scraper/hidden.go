@@ -0,0 +1,62 @@
+package scraper
+
+import (
+	"log/slog"
+
+	"github.com/go-rod/rod"
+)
+
+// markHiddenJS tags every element the rendered layout hides with the
+// data-purify-hidden attribute, which the cleaner strips. An element counts
+// as hidden when it is display:none, visibility:hidden, clipped to nothing
+// (the "sr-only" pattern), collapsed to zero size with overflow hidden, or
+// positioned entirely above or left of the document. Transparent elements
+// are kept: fade-in-on-scroll sections sit at opacity 0 until revealed.
+// Class-based hiding is only seen when stylesheets load (see
+// ScrapeRequest.RemoveHidden). Descendants of a tagged element are not
+// visited. Returns the number of elements tagged.
+const markHiddenJS = `() => {
+	const skip = new Set(['SCRIPT', 'STYLE', 'NOSCRIPT', 'TEMPLATE', 'HEAD', 'META', 'LINK', 'BR', 'WBR']);
+	const clipped = (s) =>
+		s.clip === 'rect(0px, 0px, 0px, 0px)' || s.clip === 'rect(0px 0px 0px 0px)' ||
+		s.clipPath === 'inset(50%)' || s.clipPath === 'inset(100%)';
+	const isHidden = (el) => {
+		const s = getComputedStyle(el);
+		if (s.display === 'contents') return false;
+		if (s.display === 'none') return true;
+		if (s.visibility === 'hidden' || s.visibility === 'collapse') return true;
+		const r = el.getBoundingClientRect();
+		const overflowHidden = s.overflow === 'hidden' || s.overflowX === 'hidden' || s.overflowY === 'hidden' || s.overflow === 'clip';
+		if ((r.width <= 1 || r.height <= 1) && overflowHidden) return true;
+		if (clipped(s) && s.position === 'absolute') return true;
+		if (r.width > 0 && r.height > 0 &&
+			(r.right + window.scrollX <= 0 || r.bottom + window.scrollY <= 0)) return true;
+		return false;
+	};
+	let count = 0;
+	const visit = (el) => {
+		for (const child of el.children) {
+			if (skip.has(child.tagName)) continue;
+			if (isHidden(child)) {
+				child.setAttribute('data-purify-hidden', '');
+				count++;
+				continue;
+			}
+			// SVG internals are drawn, not laid out.
+			if (child instanceof SVGElement) continue;
+			visit(child);
+		}
+	};
+	if (document.body) visit(document.body);
+	return count;
+}`
+
+// markHidden tags hidden elements before the HTML is captured (best-effort).
+func markHidden(p *rod.Page) {
+	res, err := p.Eval(markHiddenJS)
+	if err != nil {
+		slog.Debug("remove_hidden: marking failed", "error", err)
+		return
+	}
+	slog.Debug("remove_hidden: marked hidden elements", "count", res.Value.Int())
+}
@@ -1,20 +1,64 @@
 package scraper
 
 import (
+	"context"
+	"fmt"
 	"net/url"
+	"regexp"
 	"strings"
+	"sync/atomic"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
+	"github.com/use-agent/purify/adblock"
+	"github.com/use-agent/purify/models"
 )
 
 // configToProto maps human-readable config strings to Rod protocol resource types.
 var configToProto = map[string]proto.NetworkResourceType{
-	"Image":      proto.NetworkResourceTypeImage,
-	"Stylesheet": proto.NetworkResourceTypeStylesheet,
-	"Font":       proto.NetworkResourceTypeFont,
-	"Media":      proto.NetworkResourceTypeMedia,
-	"Script":     proto.NetworkResourceTypeScript,
+	"Image":       proto.NetworkResourceTypeImage,
+	"Stylesheet":  proto.NetworkResourceTypeStylesheet,
+	"Font":        proto.NetworkResourceTypeFont,
+	"Media":       proto.NetworkResourceTypeMedia,
+	"Script":      proto.NetworkResourceTypeScript,
+	"XHR":         proto.NetworkResourceTypeXHR,
+	"Fetch":       proto.NetworkResourceTypeFetch,
+	"EventSource": proto.NetworkResourceTypeEventSource,
+	"WebSocket":   proto.NetworkResourceTypeWebSocket,
+	"Manifest":    proto.NetworkResourceTypeManifest,
+	"TextTrack":   proto.NetworkResourceTypeTextTrack,
+	"Ping":        proto.NetworkResourceTypePing,
+	"Other":       proto.NetworkResourceTypeOther,
+}
+
+// filterTypes maps Rod resource types to the types used by filter-list
+// options. Documents are listed separately: subframe documents are
+// subdocuments, the main document is never blocked by filter lists.
+var filterTypes = map[proto.NetworkResourceType]adblock.ResourceType{
+	proto.NetworkResourceTypeScript:             adblock.TypeScript,
+	proto.NetworkResourceTypeImage:              adblock.TypeImage,
+	proto.NetworkResourceTypeStylesheet:         adblock.TypeStylesheet,
+	proto.NetworkResourceTypeXHR:                adblock.TypeXHR,
+	proto.NetworkResourceTypeFetch:              adblock.TypeXHR,
+	proto.NetworkResourceTypeEventSource:        adblock.TypeXHR,
+	proto.NetworkResourceTypeFont:               adblock.TypeFont,
+	proto.NetworkResourceTypeMedia:              adblock.TypeMedia,
+	proto.NetworkResourceTypeTextTrack:          adblock.TypeMedia,
+	proto.NetworkResourceTypeWebSocket:          adblock.TypeWebSocket,
+	proto.NetworkResourceTypePing:               adblock.TypePing,
+	proto.NetworkResourceTypeCSPViolationReport: adblock.TypePing,
+	proto.NetworkResourceTypeManifest:           adblock.TypeOther,
+	proto.NetworkResourceTypeOther:              adblock.TypeOther,
+}
+
+// resourceType looks up a resource type name case-insensitively.
+func resourceType(name string) (proto.NetworkResourceType, bool) {
+	for k, rt := range configToProto {
+		if strings.EqualFold(k, name) {
+			return rt, true
+		}
+	}
+	return "", false
 }
 
 // adDomains is a set of well-known ad and tracking domains to block
@@ -94,51 +138,164 @@ func isAdDomain(host string) bool {
 	return false
 }
 
-// setupHijack installs a request interceptor on the page that blocks
-// the specified resource types (images, CSS, fonts, media) and optionally
-// blocks requests to known ad/tracking domains.
-//
-// Returns the running HijackRouter so the caller can defer router.Stop().
-// Returns nil if there is nothing to block.
-func setupHijack(page *rod.Page, blockedTypes []string, blockAds bool) *rod.HijackRouter {
-	// Build O(1) lookup set from config strings
-	blocked := make(map[proto.NetworkResourceType]struct{}, len(blockedTypes))
-	for _, name := range blockedTypes {
-		if rt, ok := configToProto[name]; ok {
-			blocked[rt] = struct{}{}
+// blockPolicy decides which requests the hijack interceptor fails.
+// Allow patterns win over every block rule.
+type blockPolicy struct {
+	types    map[proto.NetworkResourceType]struct{}
+	blockAds bool
+	filters  *adblock.Engine // nil: block_ads uses adDomains
+	pageHost string          // first party for filter-list options
+	block    []*regexp.Regexp
+	allow    []*regexp.Regexp
+
+	// blocked counts the requests failed by the interceptor.
+	blocked atomic.Int64
+}
+
+// validateBlockResources rejects resource type names setupHijack does not
+// know, so typos fail the request instead of silently blocking nothing.
+func validateBlockResources(names []string) error {
+	for _, name := range names {
+		if _, ok := resourceType(name); !ok {
+			return models.NewScrapeError(
+				models.ErrCodeInvalidInput,
+				fmt.Sprintf("block_resources: unknown resource type %q", name),
+				nil,
+			)
 		}
 	}
-	if len(blocked) == 0 && !blockAds {
-		return nil
+	return nil
+}
+
+// newBlockPolicy builds the blocking policy for one scrape. The request's
+// BlockResources, when non-nil, replaces the global list of blocked resource
+// types (an empty list blocks none). With filter lists loaded, BlockAds
+// applies them instead of the built-in ad domains.
+func newBlockPolicy(globalTypes []string, filters *adblock.Engine, req *models.ScrapeRequest) *blockPolicy {
+	names := globalTypes
+	if req.BlockResources != nil {
+		names = req.BlockResources
 	}
 
-	router := page.HijackRequests()
+	p := &blockPolicy{
+		types:    make(map[proto.NetworkResourceType]struct{}, len(names)),
+		blockAds: req.BlockAds,
+		filters:  filters,
+	}
+	if u, err := url.Parse(req.URL); err == nil {
+		p.pageHost = u.Hostname()
+	}
+	for _, name := range names {
+		if rt, ok := resourceType(name); ok {
+			p.types[rt] = struct{}{}
+		}
+	}
+	for _, pattern := range req.BlockURLs {
+		p.block = append(p.block, globToRegexp(pattern))
+	}
+	for _, pattern := range req.AllowURLs {
+		p.allow = append(p.allow, globToRegexp(pattern))
+	}
+	return p
+}
 
-	// Pattern "*" + empty resourceType = intercept ALL requests, then
-	// decide per-request whether to block or continue.
-	_ = router.Add("*", "", func(ctx *rod.Hijack) {
-		// Block by resource type.
-		if _, shouldBlock := blocked[ctx.Request.Type()]; shouldBlock {
-			ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
-			return
+// empty reports whether the policy can never block anything.
+func (p *blockPolicy) empty() bool {
+	return len(p.types) == 0 && !p.blockAds && len(p.block) == 0
+}
+
+// shouldBlock applies the policy to one request. subframe reports whether
+// the request was issued by an iframe rather than the page's main frame.
+func (p *blockPolicy) shouldBlock(rawURL string, rt proto.NetworkResourceType, subframe bool) bool {
+	for _, re := range p.allow {
+		if re.MatchString(rawURL) {
+			return false
 		}
+	}
+	if _, ok := p.types[rt]; ok {
+		return true
+	}
+	for _, re := range p.block {
+		if re.MatchString(rawURL) {
+			return true
+		}
+	}
+	if !p.blockAds {
+		return false
+	}
+	if rt == proto.NetworkResourceTypeDocument && !subframe {
+		// Never block the page itself.
+		return false
+	}
+	if p.filters != nil {
+		ft, ok := filterTypes[rt]
+		if rt == proto.NetworkResourceTypeDocument {
+			ft, ok = adblock.TypeSubdocument, true
+		}
+		return ok && p.filters.Match(adblock.Request{URL: rawURL, Type: ft, SourceHost: p.pageHost})
+	}
+	u, err := url.Parse(rawURL)
+	return err == nil && isAdDomain(u.Hostname())
+}
 
-		// Block by ad domain.
-		if blockAds {
-			if u, err := url.Parse(ctx.Request.URL().String()); err == nil {
-				if isAdDomain(u.Hostname()) {
-					ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
-					return
-				}
-			}
+// globToRegexp compiles a URL glob: "*" matches any run of characters
+// (including "/"), "?" matches one character, everything else is literal.
+// Patterns match the whole URL.
+func globToRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
 		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
 
-		ctx.ContinueRequest(&proto.FetchContinueRequest{})
-	})
+// setupHijack installs a request interceptor on the page that fails every
+// request the policy blocks (resource types, URL patterns, ad domains) and
+// counts them in policy.blocked. It drives the Fetch domain directly rather
+// than through rod's HijackRouter, which hides the frame that issued a
+// request, so iframe documents can be matched as subdocuments.
+//
+// Returns a function that removes the interceptor; the caller should defer
+// it. Returns nil if there is nothing to block.
+func setupHijack(page *rod.Page, policy *blockPolicy) (stop func()) {
+	if policy.empty() {
+		return nil
+	}
 
-	// router.Run() blocks, so it must live in its own goroutine.
-	// It will exit when router.Stop() is called.
-	go router.Run()
+	ctx, cancel := context.WithCancel(page.GetContext())
+	// Subscribe before enabling so no paused request is missed: a paused
+	// request nobody answers hangs the page.
+	wait := page.Context(ctx).EachEvent(func(e *proto.FetchRequestPaused) {
+		go func() {
+			subframe := e.FrameID != page.FrameID
+			if policy.shouldBlock(e.Request.URL, e.ResourceType, subframe) {
+				policy.blocked.Add(1)
+				_ = proto.FetchFailRequest{
+					RequestID:   e.RequestID,
+					ErrorReason: proto.NetworkErrorReasonBlockedByClient,
+				}.Call(page)
+				return
+			}
+			_ = proto.FetchContinueRequest{RequestID: e.RequestID}.Call(page)
+		}()
+	})
+	if err := (proto.FetchEnable{}).Call(page); err != nil {
+		cancel()
+		return nil
+	}
+	go wait()
 
-	return router
+	return func() {
+		cancel()
+		_ = proto.FetchDisable{}.Call(page)
+	}
 }
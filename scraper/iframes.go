@@ -0,0 +1,285 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/use-agent/purify/models"
+	"golang.org/x/net/publicsuffix"
+)
+
+// frameMarkerAttr tags each <iframe> with an id so the captured frame
+// content can be spliced back into the right placeholder.
+const frameMarkerAttr = "data-purify-frame"
+
+// markFramesJS tags every iframe in the document and returns their ids and
+// resolved src URLs.
+const markFramesJS = `() => Array.from(document.querySelectorAll('iframe')).map((f, i) => {
+	f.setAttribute('data-purify-frame', String(i));
+	return {id: String(i), src: f.hasAttribute('srcdoc') ? 'about:srcdoc' : (f.src || '')};
+})`
+
+// maxFrames caps how many frames one scrape captures across all nesting
+// levels, so a page with hundreds of iframes cannot outlast its timeout.
+const maxFrames = 20
+
+// frameBudget counts the frames a scrape may still capture. It is shared by
+// the recursive calls of captureFramesRod and captureFramesHTTP.
+type frameBudget struct {
+	left int
+}
+
+func newFrameBudget() *frameBudget {
+	return &frameBudget{left: maxFrames}
+}
+
+// take uses up one frame, reporting false when none are left.
+func (b *frameBudget) take() bool {
+	if b.left <= 0 {
+		return false
+	}
+	b.left--
+	return true
+}
+
+// frameRef is an iframe found in a document.
+type frameRef struct {
+	ID  string `json:"id"`
+	Src string `json:"src"`
+}
+
+// frameAllowed applies the iframe policy to a frame URL found on pageURL.
+// srcdoc frames inherit the parent's origin and are always allowed.
+func frameAllowed(opts *models.IframeOptions, pageURL, frameURL string) bool {
+	if frameURL == "about:srcdoc" {
+		return true
+	}
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return false
+	}
+	frame, err := url.Parse(frameURL)
+	if err != nil || (frame.Scheme != "http" && frame.Scheme != "https") {
+		return false
+	}
+
+	if len(opts.Allow) > 0 {
+		host := strings.ToLower(frame.Hostname())
+		allowed := false
+		for _, pattern := range opts.Allow {
+			if ok, _ := path.Match(strings.ToLower(pattern), host); ok {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+
+	switch opts.Origins {
+	case "all":
+		return true
+	case "same-origin":
+		return page.Scheme == frame.Scheme && page.Host == frame.Host
+	default: // "same-site"
+		return registrableDomain(page.Hostname()) == registrableDomain(frame.Hostname())
+	}
+}
+
+// registrableDomain returns eTLD+1 for host, or host itself when it has
+// none (IP addresses, localhost).
+func registrableDomain(host string) string {
+	if d, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(host)); err == nil {
+		return d
+	}
+	return strings.ToLower(host)
+}
+
+// captureFramesRod marks the iframes of p and returns the rendered HTML of
+// every allowed frame, keyed by marker id. Nested frames are captured and
+// spliced recursively up to opts.MaxDepth, until budget runs out or ctx
+// ends. Must run before p.HTML() so the markers are part of the captured
+// document.
+func captureFramesRod(ctx context.Context, browser *rod.Browser, p *rod.Page, pageURL string, opts *models.IframeOptions, depth int, budget *frameBudget) map[string]string {
+	if depth >= opts.MaxDepth {
+		return nil
+	}
+	res, err := p.Eval(markFramesJS)
+	if err != nil {
+		return nil
+	}
+	var refs []frameRef
+	if err := res.Value.Unmarshal(&refs); err != nil || len(refs) == 0 {
+		return nil
+	}
+
+	quick := p.Sleeper(rod.NotFoundSleeper)
+	frames := make(map[string]string)
+	for _, ref := range refs {
+		if ctx.Err() != nil {
+			break
+		}
+		if !frameAllowed(opts, pageURL, ref.Src) {
+			continue
+		}
+		if !budget.take() {
+			break
+		}
+		el, err := quick.Element(fmt.Sprintf(`iframe[%s="%s"]`, frameMarkerAttr, ref.ID))
+		if err != nil {
+			continue
+		}
+		fp, detach, err := framePage(browser, el)
+		if err != nil {
+			slog.Debug("iframes: cannot access frame", "src", ref.Src, "error", err)
+			continue
+		}
+		fp = fp.Context(ctx)
+
+		frameURL := ref.Src
+		if frameURL == "about:srcdoc" {
+			frameURL = pageURL
+		}
+		nested := captureFramesRod(ctx, browser, fp, frameURL, opts, depth+1, budget)
+		content, err := fp.HTML()
+		detach()
+		if err != nil {
+			continue
+		}
+		frames[ref.ID] = inlineFrames(content, nested)
+	}
+	return frames
+}
+
+// framePage returns a page for an iframe's document. Same-process frames
+// are reached directly; out-of-process (cross-site) frames are attached as
+// their own target, whose id equals the frame id. detach releases the
+// extra session, if any.
+func framePage(browser *rod.Browser, el *rod.Element) (*rod.Page, func(), error) {
+	noop := func() {}
+	if fp, err := el.Frame(); err == nil {
+		if _, err := fp.Eval(`() => document.readyState`); err == nil {
+			return fp, noop, nil
+		}
+	}
+
+	node, err := proto.DOMDescribeNode{ObjectID: el.Object.ObjectID}.Call(el)
+	if err != nil {
+		return nil, noop, err
+	}
+	if node.Node.FrameID == "" {
+		return nil, noop, fmt.Errorf("iframe has no frame id")
+	}
+	fp, err := browser.PageFromTarget(proto.TargetTargetID(node.Node.FrameID))
+	if err != nil {
+		return nil, noop, err
+	}
+	return fp, func() {
+		_ = proto.TargetDetachFromTarget{SessionID: fp.SessionID}.Call(browser)
+	}, nil
+}
+
+// captureFramesHTTP marks the iframes of a statically fetched document and
+// fetches each allowed frame src over plain HTTP, one at a time, until
+// budget runs out or ctx ends. Returns the marked HTML and the frame
+// documents keyed by marker id.
+func (s *Scraper) captureFramesHTTP(ctx context.Context, rawHTML, pageURL string, opts *models.IframeOptions, depth int, budget *frameBudget) (string, map[string]string) {
+	if depth >= opts.MaxDepth || !strings.Contains(rawHTML, "<iframe") {
+		return rawHTML, nil
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawHTML))
+	if err != nil {
+		return rawHTML, nil
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return rawHTML, nil
+	}
+
+	frames := make(map[string]string)
+	done := false
+	doc.Find("iframe").Each(func(i int, f *goquery.Selection) {
+		id := fmt.Sprint(i)
+		f.SetAttr(frameMarkerAttr, id)
+		if done || ctx.Err() != nil {
+			// Keep marking, so every placeholder is stripped later.
+			return
+		}
+
+		if srcdoc, ok := f.Attr("srcdoc"); ok {
+			if done = !budget.take(); done {
+				return
+			}
+			marked, nested := s.captureFramesHTTP(ctx, srcdoc, pageURL, opts, depth+1, budget)
+			frames[id] = inlineFrames(marked, nested)
+			return
+		}
+		src, _ := f.Attr("src")
+		ref, err := base.Parse(strings.TrimSpace(src))
+		if src == "" || err != nil || !frameAllowed(opts, pageURL, ref.String()) {
+			return
+		}
+		if done = !budget.take(); done {
+			return
+		}
+		body, err := s.httpFetcher.fetch(ctx, ref.String(), "")
+		if err != nil {
+			slog.Debug("iframes: fetch failed", "src", ref.String(), "error", err)
+			return
+		}
+		marked, nested := s.captureFramesHTTP(ctx, string(body), ref.String(), opts, depth+1, budget)
+		frames[id] = inlineFrames(marked, nested)
+	})
+
+	marked, err := doc.Html()
+	if err != nil {
+		return rawHTML, nil
+	}
+	return marked, frames
+}
+
+// inlineFrames replaces each marked <iframe> that has captured content with
+// a <div data-purify-iframe> holding the frame's <body> content. Frames
+// without content keep their original placeholder, minus the marker, so
+// markers never reach the output even when nothing was captured.
+func inlineFrames(rawHTML string, frames map[string]string) string {
+	if !strings.Contains(rawHTML, frameMarkerAttr) {
+		return rawHTML
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawHTML))
+	if err != nil {
+		return rawHTML
+	}
+	doc.Find("iframe[" + frameMarkerAttr + "]").Each(func(_ int, f *goquery.Selection) {
+		id, _ := f.Attr(frameMarkerAttr)
+		f.RemoveAttr(frameMarkerAttr)
+		content, ok := frames[id]
+		if !ok {
+			return
+		}
+		frameDoc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+		if err != nil {
+			return
+		}
+		inner, err := frameDoc.Find("body").First().Html()
+		if err != nil {
+			return
+		}
+		src, _ := f.Attr("src")
+		f.ReplaceWithHtml(fmt.Sprintf(`<div data-purify-iframe="%s">%s</div>`, html.EscapeString(src), inner))
+	})
+	out, err := doc.Html()
+	if err != nil {
+		return rawHTML
+	}
+	return out
+}
@@ -0,0 +1,150 @@
+package scraper
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strconv"
+
+	"github.com/go-rod/rod"
+	"github.com/use-agent/purify/models"
+)
+
+// refAttr holds the numeric ref assigned to an interactive element. Refs
+// stay on the element for the rest of the session, so an action can target
+// an element listed in an earlier capture.
+const refAttr = "data-purify-ref"
+
+// maxInteractiveElements caps the number of elements returned per page.
+const maxInteractiveElements = 500
+
+// interactiveJS lists the visible links, buttons and form controls on the
+// page with their role, label, bounding box (document coordinates) and a
+// unique CSS selector. Elements keep a ref assigned by an earlier call;
+// new ones are numbered after the highest ref in use, in document order.
+const interactiveJS = `(attr, max) => {
+	const query = 'a[href], button, input:not([type="hidden"]), select, textarea, summary, ' +
+		'[role="button"], [role="link"], [role="checkbox"], [role="radio"], [role="switch"], ' +
+		'[role="tab"], [role="menuitem"], [role="option"], [role="combobox"], [role="textbox"], ' +
+		'[role="searchbox"], [role="slider"], [contenteditable=""], [contenteditable="true"], [onclick]';
+
+	const visible = (el) => {
+		const r = el.getBoundingClientRect();
+		if (r.width === 0 || r.height === 0) return false;
+		const s = getComputedStyle(el);
+		return s.visibility !== 'hidden' && s.display !== 'none' && parseFloat(s.opacity) !== 0;
+	};
+
+	const inputRoles = {
+		checkbox: 'checkbox', radio: 'radio', button: 'button', submit: 'button', reset: 'button',
+		image: 'button', range: 'slider', number: 'spinbutton', search: 'searchbox',
+	};
+	const roleOf = (el) => {
+		const explicit = (el.getAttribute('role') || '').trim().split(/\s+/)[0];
+		if (explicit) return explicit;
+		switch (el.tagName) {
+			case 'A': return 'link';
+			case 'BUTTON': case 'SUMMARY': return 'button';
+			case 'SELECT': return el.multiple ? 'listbox' : 'combobox';
+			case 'TEXTAREA': return 'textbox';
+			case 'INPUT': return inputRoles[(el.type || '').toLowerCase()] || 'textbox';
+		}
+		return el.isContentEditable ? 'textbox' : 'button';
+	};
+
+	const clean = (s) => (s || '').replace(/\s+/g, ' ').trim().slice(0, 100);
+	const labelOf = (el) => {
+		const aria = el.getAttribute('aria-label');
+		if (aria && aria.trim()) return clean(aria);
+		const by = el.getAttribute('aria-labelledby');
+		if (by) {
+			const text = by.split(/\s+/).map((id) => {
+				const l = document.getElementById(id);
+				return l ? l.innerText : '';
+			}).join(' ');
+			if (text.trim()) return clean(text);
+		}
+		if (el.labels && el.labels.length > 0 && el.labels[0].innerText.trim()) return clean(el.labels[0].innerText);
+		if (el.tagName === 'INPUT' && ['button', 'submit', 'reset'].includes(el.type) && el.value) return clean(el.value);
+		if (el.tagName === 'SELECT' && el.selectedOptions.length > 0) return clean(el.selectedOptions[0].text);
+		const text = el.tagName === 'INPUT' || el.tagName === 'TEXTAREA' ? '' : el.innerText;
+		if (text && text.trim()) return clean(text);
+		const img = el.querySelector && el.querySelector('img[alt]');
+		if (img && img.alt.trim()) return clean(img.alt);
+		return clean(el.getAttribute('placeholder') || el.getAttribute('title') || el.getAttribute('name') || el.getAttribute('alt'));
+	};
+
+	const unique = (sel) => {
+		try { return document.querySelectorAll(sel).length === 1; } catch (e) { return false; }
+	};
+	const selectorOf = (el) => {
+		if (el.id && unique('#' + CSS.escape(el.id))) return '#' + CSS.escape(el.id);
+		const parts = [];
+		for (let n = el; n && n.nodeType === 1; n = n.parentElement) {
+			if (n !== el && n.id && unique('#' + CSS.escape(n.id))) {
+				parts.unshift('#' + CSS.escape(n.id));
+				break;
+			}
+			let part = n.tagName.toLowerCase();
+			const parent = n.parentElement;
+			if (parent) {
+				const same = Array.from(parent.children).filter((c) => c.tagName === n.tagName);
+				if (same.length > 1) part += ':nth-of-type(' + (same.indexOf(n) + 1) + ')';
+			}
+			parts.unshift(part);
+			if (unique(parts.join(' > '))) break;
+		}
+		return parts.join(' > ');
+	};
+
+	let next = 0;
+	document.querySelectorAll('[' + attr + ']').forEach((el) => {
+		next = Math.max(next, parseInt(el.getAttribute(attr), 10) || 0);
+	});
+
+	const out = [];
+	for (const el of document.querySelectorAll(query)) {
+		if (out.length >= max) break;
+		if (el.disabled || !visible(el)) continue;
+		let ref = parseInt(el.getAttribute(attr), 10);
+		if (!ref) {
+			ref = ++next;
+			el.setAttribute(attr, String(ref));
+		}
+		const r = el.getBoundingClientRect();
+		out.push({
+			ref: ref,
+			role: roleOf(el),
+			label: labelOf(el),
+			selector: selectorOf(el),
+			bbox: {
+				x: Math.round(r.left + window.scrollX),
+				y: Math.round(r.top + window.scrollY),
+				width: Math.round(r.width),
+				height: Math.round(r.height),
+			},
+		});
+	}
+	out.sort((a, b) => a.ref - b.ref);
+	return JSON.stringify(out);
+}`
+
+// interactiveElements assigns refs to the page's interactive elements and
+// returns them (best-effort: nil on failure).
+func interactiveElements(p *rod.Page) []models.InteractiveElement {
+	res, err := p.Eval(interactiveJS, refAttr, maxInteractiveElements)
+	if err != nil {
+		slog.Debug("interactive_elements: capture failed", "error", err)
+		return nil
+	}
+	var elements []models.InteractiveElement
+	if err := json.Unmarshal([]byte(res.Value.Str()), &elements); err != nil {
+		slog.Debug("interactive_elements: decode failed", "error", err)
+		return nil
+	}
+	return elements
+}
+
+// refSelector is the CSS selector of the element carrying ref.
+func refSelector(ref int) string {
+	return "[" + refAttr + `="` + strconv.Itoa(ref) + `"]`
+}
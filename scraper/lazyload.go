@@ -0,0 +1,48 @@
+package scraper
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+const (
+	// lazyLoadMaxSteps bounds the scroll on infinite-scroll pages.
+	lazyLoadMaxSteps = 40
+	// lazyLoadStepDelay gives IntersectionObserver callbacks and image
+	// requests time to fire between scroll steps.
+	lazyLoadStepDelay = 150 * time.Millisecond
+)
+
+// lazyLoadJS switches native lazy loading to eager and scrolls through the
+// page one viewport at a time so IntersectionObserver-based loaders fire,
+// then returns to the top. Returns the number of scroll steps taken.
+const lazyLoadJS = `async (maxSteps, delay) => {
+	document.querySelectorAll('img[loading="lazy"], iframe[loading="lazy"]').forEach(el => { el.loading = 'eager'; });
+	const sleep = (ms) => new Promise(r => setTimeout(r, ms));
+	const step = Math.max(window.innerHeight * 0.8, 200);
+	let y = window.scrollY, steps = 0;
+	while (steps < maxSteps) {
+		const height = Math.max(document.body ? document.body.scrollHeight : 0, document.documentElement.scrollHeight);
+		if (y + window.innerHeight >= height) break;
+		y += step;
+		window.scrollTo(0, y);
+		steps++;
+		await sleep(delay);
+	}
+	window.scrollTo(0, 0);
+	return steps;
+}`
+
+// lazyLoad scrolls the page into view step by step to trigger lazy-loaded
+// images and content, then waits for the DOM to settle (best-effort).
+func lazyLoad(p *rod.Page) {
+	res, err := p.Eval(lazyLoadJS, lazyLoadMaxSteps, lazyLoadStepDelay.Milliseconds())
+	if err != nil {
+		slog.Debug("lazy_load: scrolling failed", "error", err)
+		return
+	}
+	slog.Debug("lazy_load: scrolled page", "steps", res.Value.Int())
+	_ = p.WaitDOMStable(300*time.Millisecond, 0.1)
+}
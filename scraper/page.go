@@ -18,15 +18,19 @@ import (
 
 // DoScrape is the top-level orchestrator.
 //
-// If the multi-engine dispatcher is configured AND the request has no Actions
-// AND no CDPURL, it delegates to the dispatcher for a faster path (HTTP-first
-// with Rod fallback via engine racing). Otherwise it falls through to the
-// direct Rod-based scraping path.
+// If the multi-engine dispatcher is configured AND the request needs nothing
+// that only a browser session provides (see requiresBrowser), it delegates to
+// the dispatcher for a faster path (HTTP-first with Rod fallback via engine
+// racing). Otherwise it falls through to the direct Rod-based scraping path.
 func (s *Scraper) DoScrape(ctx context.Context, req *models.ScrapeRequest) (*ScrapeResult, error) {
+	if err := validateBlockResources(req.BlockResources); err != nil {
+		return nil, err
+	}
+
 	// ── 0. Multi-engine dispatch ────────────────────────────────────
-	// If the dispatcher is configured AND the request has no Actions AND
-	// no CDPURL, delegate to the multi-engine dispatcher for a faster path.
-	if s.dispatcher != nil && len(req.Actions) == 0 && req.CDPURL == "" {
+	// If the dispatcher is configured AND the request can be served without
+	// a browser session, delegate to the multi-engine dispatcher.
+	if s.dispatcher != nil && !requiresBrowser(req) {
 		timeout := time.Duration(req.Timeout) * time.Second
 		if timeout > s.scraperCfg.MaxTimeout {
 			timeout = s.scraperCfg.MaxTimeout
@@ -48,6 +52,14 @@ func (s *Scraper) DoScrape(ctx context.Context, req *models.ScrapeRequest) (*Scr
 			Cookies: cookies,
 			Timeout: timeout,
 			Stealth: req.Stealth,
+
+			BlockAds:       req.BlockAds,
+			BlockResources: req.BlockResources,
+			BlockURLs:      req.BlockURLs,
+			AllowURLs:      req.AllowURLs,
+			Emulation:      emulationFor(req),
+			RemoveHidden:   req.RemoveHidden,
+			LazyLoad:       req.LazyLoad,
 		}
 
 		dispatchCtx, dispatchCancel := context.WithTimeout(ctx, timeout)
@@ -55,23 +67,62 @@ func (s *Scraper) DoScrape(ctx context.Context, req *models.ScrapeRequest) (*Scr
 
 		result, err := s.dispatcher.Dispatch(dispatchCtx, fetchReq)
 		if err == nil {
-			return &ScrapeResult{
+			sr := &ScrapeResult{
 				RawHTML:     result.HTML,
 				Title:       result.Title,
 				StatusCode:  result.StatusCode,
 				FinalURL:    result.FinalURL,
 				EngineUsed:  result.EngineName,
 				FetchMethod: result.EngineName,
-			}, nil
+
+				BlockedRequests: result.BlockedRequests,
+			}
+
+			// ── 0a. Inline iframes fetched over HTTP ─────────────────
+			if req.Iframes != nil {
+				marked, frames := s.captureFramesHTTP(dispatchCtx, sr.RawHTML, sr.FinalURL, req.Iframes, 0, newFrameBudget())
+				sr.RawHTML = inlineFrames(marked, frames)
+			}
+
+			// ── 0b. Pagination over plain links ─────────────────────
+			// "Load more" buttons need a browser session, so such pages
+			// are re-scraped on the rod path below.
+			if req.Paginate == nil {
+				return sr, nil
+			}
+			if pages, ok := s.followPagesHTTP(dispatchCtx, req, sr, *fetchReq); ok {
+				sr.Pages = pages
+				return sr, nil
+			}
+			slog.Debug("paginate: load-more button found, switching to browser session",
+				"url", req.URL)
+		} else {
+			// Dispatcher failed entirely — fall through to existing rod logic.
+			slog.Warn("dispatcher failed, falling back to direct rod scrape",
+				"url", req.URL, "error", err)
 		}
-		// Dispatcher failed entirely — fall through to existing rod logic.
-		slog.Warn("dispatcher failed, falling back to direct rod scrape",
-			"url", req.URL, "error", err)
 	}
 
 	return s.doScrapeRod(ctx, req)
 }
 
+// requiresBrowser reports whether the request uses features that only the
+// direct rod path implements: actions, a user CDP endpoint, downloads,
+// rendered iframe capture, shadow DOM serialization, consent handling or
+// timezone/geolocation emulation.
+func requiresBrowser(req *models.ScrapeRequest) bool {
+	return len(req.Actions) > 0 ||
+		req.CDPURL != "" ||
+		req.Consent != "" ||
+		req.TimezoneID != "" ||
+		req.Geolocation != nil ||
+		req.Downloads != nil ||
+		(req.Iframes != nil && !req.Iframes.FetchHTTP) ||
+		req.FlattenShadowDOM ||
+		req.OutputFormat == "accessibility" ||
+		req.InteractiveElements
+}
+
 // DoScrapeRod is the direct rod-based scraping path. It is exported so
 // that the engine.RodEngine callback in main.go can call it without
 // triggering the dispatcher (avoiding infinite recursion).
@@ -88,12 +139,15 @@ func (s *Scraper) DoScrapeRod(ctx context.Context, req *models.ScrapeRequest) (*
 //  2. Acquire page           – borrow a tab from the pool (or create one)
 //  3. DEFER: cleanup         – about:blank + return to pool (leak prevention)
 //  4. Stealth injection      – mask navigator.webdriver etc. (before navigation!)
+//     (+ headers, cookies, device/locale/timezone/geolocation emulation)
 //  5. Hijack mount           – block images/CSS/fonts/media (before navigation!)
 //  6. Context binding        – propagate timeout to all Rod operations
 //  7. Idle listener setup    – MUST be registered before Navigate to capture all requests
 //  8. Navigate               – triggers page load
-//  9. Wait                   – network idle or DOM stable
-//  10. Extract               – page.HTML() + document.title
+//  9. Wait                   – network idle or DOM stable (+ consent, actions)
+//  10. Extract               – page.HTML() + document.title (+ inlined iframes, accessibility tree, interactive elements)
+//  11. Metadata              – final URL (best-effort)
+//  12. Paginate              – follow next links / "Load more" in the same tab
 //
 // Why this order matters:
 //   - Steps 4-5 MUST happen before step 8: stealth JS and resource blocking only
@@ -103,6 +157,10 @@ func (s *Scraper) DoScrapeRod(ctx context.Context, req *models.ScrapeRequest) (*
 //     wait would return instantly (false idle).
 //   - Step 3's about:blank uses the ORIGINAL page reference (without request
 //     context), so cleanup succeeds even if the request context has expired.
+//
+// Requests with Downloads or Geolocation set replace steps 2-3 with a
+// dedicated tab in an incognito context. It holds a pool slot while open
+// and is disposed instead of returned to the pool.
 func (s *Scraper) doScrapeRod(ctx context.Context, req *models.ScrapeRequest) (*ScrapeResult, error) {
 	// ── 1. Timeout guard ──────────────────────────────────────────────
 	timeout := time.Duration(req.Timeout) * time.Second
@@ -121,6 +179,58 @@ func (s *Scraper) doScrapeRod(ctx context.Context, req *models.ScrapeRequest) (*
 	s.activePages.Add(1)
 	defer s.activePages.Add(-1)
 
+	// Downloads need their own browser context (see downloadSession), so
+	// such requests use a dedicated tab in place of a pooled one.
+	if req.Downloads != nil {
+		release, err := s.takePoolSlot(ctx)
+		if err != nil {
+			return nil, models.NewScrapeError(
+				models.ErrCodeTimeout,
+				"timed out waiting for a free page",
+				err,
+			)
+		}
+		defer release()
+		dl, err := s.openDownloadSession()
+		if err != nil {
+			return nil, models.NewScrapeError(
+				models.ErrCodeBrowserCrash,
+				"failed to open download session",
+				err,
+			)
+		}
+		defer dl.close()
+		return s.scrapeOnPage(ctx, req, dl.browser, dl.page, dl)
+	}
+
+	// Likewise geolocation: its permission grant covers the whole origin in
+	// a browser context, so it must not be given to the pool's context.
+	if req.Geolocation != nil {
+		release, err := s.takePoolSlot(ctx)
+		if err != nil {
+			return nil, models.NewScrapeError(
+				models.ErrCodeTimeout,
+				"timed out waiting for a free page",
+				err,
+			)
+		}
+		defer release()
+		isolated, page, err := s.openIsolatedPage()
+		if err != nil {
+			return nil, models.NewScrapeError(
+				models.ErrCodeBrowserCrash,
+				"failed to open browser context",
+				err,
+			)
+		}
+		defer func() {
+			if err := isolated.Close(); err != nil {
+				slog.Warn("cleanup: failed to dispose browser context", "error", err)
+			}
+		}()
+		return s.scrapeOnPage(ctx, req, isolated, page, nil)
+	}
+
 	page, acquireErr := s.pagePool.Get(func() (*rod.Page, error) {
 		return s.browser.Page(proto.TargetCreateTarget{})
 	})
@@ -134,6 +244,9 @@ func (s *Scraper) doScrapeRod(ctx context.Context, req *models.ScrapeRequest) (*
 
 	// ── 3. CRITICAL DEFER: prevent DOM memory leak + guarantee pool return
 	defer func() {
+		// Drop device/locale/timezone/geolocation overrides and any
+		// viewport set by a set_viewport action.
+		resetEmulation(s.browser, page, req, emulationFor(req))
 		if navErr := page.Navigate("about:blank"); navErr != nil {
 			slog.Warn("cleanup: failed to navigate to about:blank",
 				"error", navErr,
@@ -142,6 +255,13 @@ func (s *Scraper) doScrapeRod(ctx context.Context, req *models.ScrapeRequest) (*
 		s.pagePool.Put(page)
 	}()
 
+	return s.scrapeOnPage(ctx, req, s.browser, page, nil)
+}
+
+// scrapeOnPage runs steps 4-12 of doScrapeRod on an acquired tab. browser
+// is the browser context the tab belongs to; dl is non-nil when the tab
+// belongs to a download session.
+func (s *Scraper) scrapeOnPage(ctx context.Context, req *models.ScrapeRequest, browser *rod.Browser, page *rod.Page, dl *downloadSession) (*ScrapeResult, error) {
 	// ── 4. Stealth injection ──────────────────────────────────────────
 	if req.Stealth {
 		if _, evalErr := page.EvalOnNewDocument(stealth.JS); evalErr != nil {
@@ -187,10 +307,17 @@ func (s *Scraper) doScrapeRod(ctx context.Context, req *models.ScrapeRequest) (*
 		}.Call(page)
 	}
 
-	// ── 5. Mount hijack router (blocks Image/Stylesheet/Font/Media + ads) ──
-	router := setupHijack(page, s.scraperCfg.BlockedResourceTypes, req.BlockAds)
-	if router != nil {
-		defer func() { _ = router.Stop() }()
+	// ── 4d. Device, locale, timezone and geolocation emulation ──────
+	if em := emulationFor(req); em != nil {
+		if err := applyEmulation(browser, page, em, req.URL); err != nil {
+			return nil, categorizeEmulationError(err)
+		}
+	}
+
+	// ── 5. Intercept requests (resource types, URL patterns, ads) ───
+	blocking := newBlockPolicy(s.scraperCfg.BlockedResourceTypes, s.filters, req)
+	if stop := setupHijack(page, blocking); stop != nil {
+		defer stop()
 	}
 
 	// ── 6. Bind request context to page ───────────────────────────────
@@ -209,6 +336,11 @@ func (s *Scraper) doScrapeRod(ctx context.Context, req *models.ScrapeRequest) (*
 	// NavigationHistory, which is always available without any event listeners.
 	var statusCode int
 
+	// ── 7c. Dialog policy + popup tracking ──────────────────────────
+	handleDialogs(ctx, page, req.Dialogs)
+	popups := trackPopups(ctx, s.browser, page)
+	defer popups.close()
+
 	// ── 8. Navigate ───────────────────────────────────────────────────
 	var navErr error
 	if navErr = p.Navigate(req.URL); navErr != nil {
@@ -229,18 +361,16 @@ func (s *Scraper) doScrapeRod(ctx context.Context, req *models.ScrapeRequest) (*
 	// ── 9b. Collect status code via JS (best-effort) ────────────────
 	// Use performance.getEntriesByType("navigation") to get the HTTP status
 	// code without needing CDP event listeners.
-	if res, err := p.Eval(`() => {
-		try {
-			const entries = performance.getEntriesByType("navigation");
-			if (entries.length > 0) return entries[0].responseStatus || 0;
-		} catch(e) {}
-		return 0;
-	}`); err == nil {
+	if res, err := p.Eval(navigationStatusJS); err == nil {
 		statusCode = res.Value.Int()
 	}
 
-	// ── 9c. Remove overlays (cookie banners, popups) ────────────────
-	if req.RemoveOverlays {
+	// ── 9c. Answer cookie consent, remove overlays ──────────────────
+	var consentManager string
+	if req.Consent != "" {
+		consentManager = handleConsent(ctx, p, req.Consent)
+	}
+	if req.RemoveOverlays || (req.Consent != "" && consentManager == "") {
 		removeOverlays(p)
 	}
 
@@ -251,11 +381,61 @@ func (s *Scraper) doScrapeRod(ctx context.Context, req *models.ScrapeRequest) (*
 		}
 	}
 
+	// ── 9e. Wait for downloads triggered by the actions ─────────────
+	var downloads []DownloadResult
+	if dl != nil {
+		dl.wait(ctx)
+		downloads = dl.results(int64(req.Downloads.MaxSizeMB) << 20)
+	}
+
+	// ── 9f. Follow the newest popup tab ─────────────────────────────
+	if req.FollowPopups {
+		if popup := popups.latest(ctx, req.Dialogs); popup != nil {
+			p = popup
+			if res, err := p.Eval(navigationStatusJS); err == nil {
+				statusCode = res.Value.Int()
+			}
+		}
+	}
+
+	// ── 9g. Trigger lazy loading by scrolling through the page ──────
+	if req.LazyLoad {
+		lazyLoad(p)
+	}
+
+	// ── 9h. Mark elements hidden by the rendered layout ─────────────
+	if req.RemoveHidden {
+		markHidden(p)
+	}
+
+	// ── 9i. Capture iframe documents (marks placeholders) ───────────
+	var frames map[string]string
+	if req.Iframes != nil {
+		frames = captureFramesRod(ctx, s.browser, p, evalStringOrEmpty(p, `() => window.location.href`), req.Iframes, 0, newFrameBudget())
+	}
+
 	// ── 10. Extract rendered HTML ─────────────────────────────────────
-	rawHTML, htmlErr := p.HTML()
+	rawHTML, htmlErr := captureHTML(p, req.FlattenShadowDOM)
 	if htmlErr != nil {
 		return nil, categorizeError(htmlErr, "failed to extract page HTML")
 	}
+	rawHTML = inlineFrames(rawHTML, frames)
+
+	// ── 10b. Accessibility tree ──────────────────────────────────────
+	var axTree string
+	if req.OutputFormat == "accessibility" {
+		tree, err := accessibilitySnapshot(p)
+		if err != nil {
+			return nil, categorizeError(err, "failed to capture accessibility tree")
+		}
+		axTree = tree
+	}
+
+	// ── 10c. Interactive elements (assigns refs) ─────────────────────
+	var interactive []models.InteractiveElement
+	if req.InteractiveElements {
+		interactive = interactiveElements(p)
+	}
 
 	// ── 11. Extract title and final URL (best-effort) ────────────────
 	title := evalStringOrEmpty(p, `() => document.title`)
@@ -264,15 +444,38 @@ func (s *Scraper) doScrapeRod(ctx context.Context, req *models.ScrapeRequest) (*
 		finalURL = req.URL
 	}
 
+	// ── 12. Pagination (same browser session) ────────────────────────
+	var pages []PageResult
+	if req.Paginate != nil {
+		rawHTML, pages = followPagesRod(ctx, p, req.Paginate, finalURL, rawHTML)
+	}
+
 	return &ScrapeResult{
-		RawHTML:      rawHTML,
-		Title:        title,
-		StatusCode:   statusCode,
-		FinalURL:     finalURL,
-		FetchMethod:  "browser",
+		RawHTML:     rawHTML,
+		Title:       title,
+		StatusCode:  statusCode,
+		FinalURL:    finalURL,
+		FetchMethod: "browser",
+		Pages:       pages,
+		Downloads:   downloads,
+
+		Accessibility:       axTree,
+		InteractiveElements: interactive,
+		BlockedRequests:     int(blocking.blocked.Load()),
+		ConsentManager:      consentManager,
 	}, nil
 }
 
+// navigationStatusJS reads the HTTP status of the current document from the
+// Navigation Timing API.
+const navigationStatusJS = `() => {
+	try {
+		const entries = performance.getEntriesByType("navigation");
+		if (entries.length > 0) return entries[0].responseStatus || 0;
+	} catch(e) {}
+	return 0;
+}`
+
 // evalStringOrEmpty evaluates a JS expression and returns the string result,
 // swallowing any errors (useful for optional metadata extraction).
 func evalStringOrEmpty(page *rod.Page, js string) string {
@@ -316,12 +519,32 @@ func (s *Scraper) doScrapeWithCDP(ctx context.Context, req *models.ScrapeRequest
 		)
 	}
 	defer func() {
+		resetEmulation(browser, page, req, emulationFor(req))
 		_ = page.Close()
 	}()
 
+	// Device, locale, timezone and geolocation emulation.
+	if em := emulationFor(req); em != nil {
+		if err := applyEmulation(browser, page, em, req.URL); err != nil {
+			return nil, categorizeEmulationError(err)
+		}
+	}
+
+	// Request blocking: only what the request asks for. The global resource
+	// types are for the pool's browser, not the caller's own Chrome.
+	blocking := newBlockPolicy(nil, s.filters, req)
+	if stop := setupHijack(page, blocking); stop != nil {
+		defer stop()
+	}
+
 	// Bind context for timeout.
 	p := page.Context(ctx)
 
+	// Dialog policy + popup tracking.
+	handleDialogs(ctx, page, req.Dialogs)
+	popups := trackPopups(ctx, browser, page)
+	defer popups.close()
+
 	// Navigate.
 	if err := p.Navigate(req.URL); err != nil {
 		return nil, categorizeError(err, "navigation to target URL failed")
@@ -335,8 +558,12 @@ func (s *Scraper) doScrapeWithCDP(ctx context.Context, req *models.ScrapeRequest
 		_ = p.WaitDOMStable(300*time.Millisecond, 0.1)
 	}
 
-	// Remove overlays if requested.
-	if req.RemoveOverlays {
+	// Answer cookie consent, remove overlays if requested.
+	var consentManager string
+	if req.Consent != "" {
+		consentManager = handleConsent(ctx, p, req.Consent)
+	}
+	if req.RemoveOverlays || (req.Consent != "" && consentManager == "") {
 		removeOverlays(p)
 	}
 
@@ -347,11 +574,47 @@ func (s *Scraper) doScrapeWithCDP(ctx context.Context, req *models.ScrapeRequest
 		}
 	}
 
+	// Follow the newest popup tab.
+	if req.FollowPopups {
+		if popup := popups.latest(ctx, req.Dialogs); popup != nil {
+			p = popup
+		}
+	}
+
+	// Trigger lazy loading, mark elements hidden by the rendered layout.
+	if req.LazyLoad {
+		lazyLoad(p)
+	}
+	if req.RemoveHidden {
+		markHidden(p)
+	}
+
+	// Capture iframe documents (marks placeholders).
+	var frames map[string]string
+	if req.Iframes != nil {
+		frames = captureFramesRod(ctx, browser, p, evalStringOrEmpty(p, `() => window.location.href`), req.Iframes, 0, newFrameBudget())
+	}
+
 	// Extract.
-	rawHTML, htmlErr := p.HTML()
+	rawHTML, htmlErr := captureHTML(p, req.FlattenShadowDOM)
 	if htmlErr != nil {
 		return nil, categorizeError(htmlErr, "failed to extract page HTML")
 	}
+	rawHTML = inlineFrames(rawHTML, frames)
+
+	var axTree string
+	if req.OutputFormat == "accessibility" {
+		tree, err := accessibilitySnapshot(p)
+		if err != nil {
+			return nil, categorizeError(err, "failed to capture accessibility tree")
+		}
+		axTree = tree
+	}
+
+	var interactive []models.InteractiveElement
+	if req.InteractiveElements {
+		interactive = interactiveElements(p)
+	}
 
 	title := evalStringOrEmpty(p, `() => document.title`)
 	finalURL := evalStringOrEmpty(p, `() => window.location.href`)
@@ -359,10 +622,21 @@ func (s *Scraper) doScrapeWithCDP(ctx context.Context, req *models.ScrapeRequest
 		finalURL = req.URL
 	}
 
+	var pages []PageResult
+	if req.Paginate != nil {
+		rawHTML, pages = followPagesRod(ctx, p, req.Paginate, finalURL, rawHTML)
+	}
+
 	return &ScrapeResult{
 		RawHTML:  rawHTML,
 		Title:    title,
 		FinalURL: finalURL,
+		Pages:    pages,
+
+		Accessibility:       axTree,
+		InteractiveElements: interactive,
+		BlockedRequests:     int(blocking.blocked.Load()),
+		ConsentManager:      consentManager,
 	}, nil
 }
 
@@ -403,6 +677,16 @@ func removeOverlays(p *rod.Page) {
 	_, _ = p.Eval(js)
 }
 
+// categorizeEmulationError passes through input errors from applyEmulation
+// and reports CDP failures as browser errors.
+func categorizeEmulationError(err error) *models.ScrapeError {
+	var se *models.ScrapeError
+	if errors.As(err, &se) {
+		return se
+	}
+	return models.NewScrapeError(models.ErrCodeBrowserCrash, "failed to apply emulation", err)
+}
+
 // categorizeError wraps raw errors into typed ScrapeErrors so the API layer
 // can map them to appropriate HTTP status codes.
 func categorizeError(err error, msg string) *models.ScrapeError {
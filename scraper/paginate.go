@@ -0,0 +1,324 @@
+package scraper
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/use-agent/purify/engine"
+	"github.com/use-agent/purify/models"
+	"github.com/use-agent/purify/selector"
+)
+
+// pagerSelectors are common "next page" link patterns used by CMSs, blog
+// engines and search result pages. They are tried in order after rel=next.
+var pagerSelectors = []string{
+	".pagination a.next",
+	".pagination .next a",
+	".pagination li.next a",
+	".pager .next a",
+	".pager__item--next a",
+	".nav-links a.next",
+	"a.next.page-numbers",
+	"a.pagination-next",
+	"a.next-page",
+	"a[aria-label='Next']",
+	"a[aria-label='Next page']",
+	"a[aria-label='next page']",
+}
+
+// nextTextPattern matches the visible text of "next page" links.
+// It is shared with the in-browser detector, so it must stay valid in both
+// Go RE2 and JavaScript regex syntax.
+const nextTextPattern = `^\s*(next|next page|next ›|next »|older posts|older entries|›|»|→|下一页|下一頁|次へ|suivant|weiter|siguiente|próxima)\s*$`
+
+// loadMoreTextPattern matches the visible text of "Load more" buttons.
+const loadMoreTextPattern = `^\s*(load more|show more|view more|see more|more results|加载更多|显示更多|もっと見る|plus de résultats|mehr laden)\b`
+
+var (
+	reNextText     = regexp.MustCompile(`(?i)` + nextTextPattern)
+	reLoadMoreText = regexp.MustCompile(`(?i)` + loadMoreTextPattern)
+)
+
+// findNextJS locates the next page inside the browser. steps is the parsed
+// next_selector, resolved with resolveSelectorJS, or null for detection. It
+// returns {kind: "link", href} for navigable links, {kind: "button"} for
+// clickable elements (marked with data-purify-next), or null when nothing
+// is found.
+const findNextJS = `(steps, roles, pagerSels, nextPat, morePat) => {
+	const resolve = ` + resolveSelectorJS + `;
+	document.querySelectorAll('[data-purify-next]').forEach(e => e.removeAttribute('data-purify-next'));
+	const visible = (el) => !!(el.offsetWidth || el.offsetHeight || el.getClientRects().length);
+	const disabled = (el) => el.disabled || el.getAttribute('aria-disabled') === 'true' ||
+		/(^|\s)disabled(\s|$)/.test(el.className || '');
+	const pick = (el) => {
+		if (!el || disabled(el)) return null;
+		const a = el.closest('a[href]');
+		const raw = a ? a.getAttribute('href') : '';
+		if (a && raw && raw !== '#' && !raw.startsWith('javascript:')) {
+			return {kind: 'link', href: a.href};
+		}
+		if (!visible(el)) return null;
+		el.setAttribute('data-purify-next', '1');
+		return {kind: 'button', href: ''};
+	};
+	if (steps) return pick(resolve(steps, roles, false));
+
+	const rel = document.querySelector('link[rel~="next"][href], a[rel~="next"][href]');
+	if (rel) return {kind: 'link', href: rel.href};
+	for (const s of pagerSels) {
+		const found = pick(document.querySelector(s));
+		if (found) return found;
+	}
+	const nextRe = new RegExp(nextPat, 'i');
+	const moreRe = new RegExp(morePat, 'i');
+	const candidates = document.querySelectorAll('a[href], button, [role="button"]');
+	for (const el of candidates) {
+		if (nextRe.test(el.textContent || '') && el.tagName === 'A') {
+			const found = pick(el);
+			if (found) return found;
+		}
+	}
+	for (const el of candidates) {
+		if (moreRe.test((el.textContent || '').trim())) {
+			const found = pick(el);
+			if (found) return found;
+		}
+	}
+	return null;
+}`
+
+// followPagesRod follows pagination inside the current browser session,
+// starting from the already-captured first page. "Next" links are navigated
+// in the same tab; "Load more" buttons are clicked and the grown DOM replaces
+// the most recently captured page, since it already contains earlier content.
+//
+// Returns the updated first-page HTML (changed only by "Load more" clicks on
+// the first page) and any additional pages. Pagination errors are logged and
+// end pagination; they never fail the scrape.
+func followPagesRod(ctx context.Context, p *rod.Page, opts *models.PaginateOptions, firstURL, firstHTML string) (string, []PageResult) {
+	var steps []selector.Step
+	if opts.NextSelector != "" {
+		sel, err := selector.Parse(opts.NextSelector)
+		if err != nil {
+			slog.Warn("paginate: invalid next_selector", "error", err)
+			return firstHTML, nil
+		}
+		steps = sel.Steps
+	}
+
+	visited := map[string]struct{}{normalizePageURL(firstURL): {}}
+	currentHTML := firstHTML
+	var pages []PageResult
+
+	for captured := 1; captured < opts.MaxPages && ctx.Err() == nil; captured++ {
+		res, err := p.Eval(findNextJS, steps, selector.ImplicitRoles, pagerSelectors, nextTextPattern, loadMoreTextPattern)
+		if err != nil || res.Value.Nil() {
+			break
+		}
+
+		switch res.Value.Get("kind").Str() {
+		case "link":
+			href := res.Value.Get("href").Str()
+			key := normalizePageURL(href)
+			if _, seen := visited[key]; seen {
+				return firstHTML, pages
+			}
+			visited[key] = struct{}{}
+
+			if err := p.Navigate(href); err != nil {
+				slog.Warn("paginate: navigation failed", "url", href, "error", err)
+				return firstHTML, pages
+			}
+			_ = p.WaitDOMStable(300*time.Millisecond, 0.1)
+
+			html, err := p.HTML()
+			if err != nil {
+				return firstHTML, pages
+			}
+			finalURL := evalStringOrEmpty(p, `() => window.location.href`)
+			if finalURL == "" {
+				finalURL = href
+			}
+			pages = append(pages, PageResult{
+				RawHTML:  html,
+				Title:    evalStringOrEmpty(p, `() => document.title`),
+				FinalURL: finalURL,
+			})
+			currentHTML = html
+
+		case "button":
+			el, err := p.Element("[data-purify-next]")
+			if err != nil {
+				return firstHTML, pages
+			}
+			if err := el.Click(proto.InputMouseButtonLeft, 1); err != nil {
+				slog.Debug("paginate: load-more click failed", "error", err)
+				return firstHTML, pages
+			}
+			_ = p.WaitDOMStable(500*time.Millisecond, 0.1)
+
+			html, err := p.HTML()
+			if err != nil || html == currentHTML {
+				// Nothing new was loaded — the list is exhausted.
+				return firstHTML, pages
+			}
+			currentHTML = html
+			if len(pages) == 0 {
+				firstHTML = html
+			} else {
+				pages[len(pages)-1].RawHTML = html
+			}
+
+		default:
+			return firstHTML, pages
+		}
+	}
+
+	return firstHTML, pages
+}
+
+// followPagesHTTP follows plain "next" links through the multi-engine
+// dispatcher. It returns ok=false when the page only offers a "Load more"
+// button, which needs a browser session; the caller then re-runs the scrape
+// on the rod path.
+func (s *Scraper) followPagesHTTP(ctx context.Context, req *models.ScrapeRequest, first *ScrapeResult, fetchReq engine.FetchRequest) ([]PageResult, bool) {
+	visited := map[string]struct{}{normalizePageURL(first.FinalURL): {}}
+	currentHTML, currentURL := first.RawHTML, first.FinalURL
+	var pages []PageResult
+
+	for captured := 1; captured < req.Paginate.MaxPages; captured++ {
+		nextURL, isButton := findNextPage(currentHTML, currentURL, req.Paginate.NextSelector)
+		if isButton {
+			if len(pages) == 0 {
+				return nil, false
+			}
+			break
+		}
+		if nextURL == "" {
+			break
+		}
+		key := normalizePageURL(nextURL)
+		if _, seen := visited[key]; seen {
+			break
+		}
+		visited[key] = struct{}{}
+
+		fetchReq.URL = nextURL
+		result, err := s.dispatcher.Dispatch(ctx, &fetchReq)
+		if err != nil {
+			slog.Warn("paginate: failed to fetch next page", "url", nextURL, "error", err)
+			break
+		}
+		pages = append(pages, PageResult{
+			RawHTML:  result.HTML,
+			Title:    result.Title,
+			FinalURL: result.FinalURL,
+		})
+		currentHTML, currentURL = result.HTML, result.FinalURL
+	}
+	return pages, true
+}
+
+// findNextPage inspects static HTML for the next page. It mirrors findNextJS:
+// an explicit selector (any selector package syntax) first, then rel=next,
+// pager patterns, "next" link text and finally "Load more" buttons
+// (reported via isButton).
+func findNextPage(rawHTML, pageURL, nextSelector string) (nextURL string, isButton bool) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawHTML))
+	if err != nil {
+		return "", false
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", false
+	}
+
+	resolve := func(s *goquery.Selection) (string, bool) {
+		a := s.Closest("a[href]")
+		if a.Length() == 0 {
+			return "", false
+		}
+		href, _ := a.Attr("href")
+		if href == "" || href == "#" || strings.HasPrefix(href, "javascript:") {
+			return "", false
+		}
+		u, err := base.Parse(href)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return "", false
+		}
+		return u.String(), true
+	}
+
+	if nextSelector != "" {
+		sel, err := selector.Parse(nextSelector)
+		if err != nil {
+			return "", false
+		}
+		matches := sel.QueryAll(doc.Get(0))
+		if len(matches) == 0 {
+			return "", false
+		}
+		match := doc.FindNodes(matches[0])
+		if href, ok := resolve(match); ok {
+			return href, false
+		}
+		return "", true
+	}
+
+	if rel := doc.Find(`link[rel~="next"][href], a[rel~="next"][href]`).First(); rel.Length() > 0 {
+		if href, ok := resolve(rel); ok {
+			return href, false
+		}
+		if href, ok := rel.Attr("href"); ok {
+			if u, err := base.Parse(href); err == nil {
+				return u.String(), false
+			}
+		}
+	}
+
+	for _, sel := range pagerSelectors {
+		if href, ok := resolve(doc.Find(sel).First()); ok {
+			return href, false
+		}
+	}
+
+	doc.Find("a[href]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if reNextText.MatchString(s.Text()) {
+			if href, ok := resolve(s); ok {
+				nextURL = href
+				return false
+			}
+		}
+		return true
+	})
+	if nextURL != "" {
+		return nextURL, false
+	}
+
+	doc.Find(`button, [role="button"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if reLoadMoreText.MatchString(strings.TrimSpace(s.Text())) {
+			isButton = true
+			return false
+		}
+		return true
+	})
+	return "", isButton
+}
+
+// normalizePageURL strips the fragment so "page?p=2" and "page?p=2#top" are
+// treated as the same page when detecting pagination loops.
+func normalizePageURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.Fragment = ""
+	return u.String()
+}
@@ -0,0 +1,121 @@
+package scraper
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// handleDialogs answers every alert/confirm/prompt/beforeunload dialog on
+// the page according to policy ("accept" or "dismiss") until ctx ends.
+// An empty policy leaves dialogs alone.
+func handleDialogs(ctx context.Context, page *rod.Page, policy string) {
+	if policy == "" {
+		return
+	}
+	accept := policy == "accept"
+	go page.Context(ctx).EachEvent(func(e *proto.PageJavascriptDialogOpening) {
+		slog.Debug("dialog handled", "type", e.Type, "message", e.Message, "accept", accept)
+		_ = proto.PageHandleJavaScriptDialog{Accept: accept}.Call(page)
+	})()
+}
+
+// popupWait bounds how long latest waits for a popup that the page asked
+// to open (Page.windowOpen) but whose TargetCreated event has not arrived
+// yet when the actions finish.
+const popupWait = 2 * time.Second
+
+// popupTracker records tabs opened by a page (target=_blank links,
+// window.open) so the scraper can follow the newest one and close the rest.
+type popupTracker struct {
+	browser *rod.Browser
+	stop    context.CancelFunc
+	opened  chan struct{} // signalled when a popup is recorded
+
+	mu        sync.Mutex
+	ids       []proto.TargetTargetID
+	requested bool // the page asked to open a window
+}
+
+// trackPopups starts recording targets whose opener is page. Call close to
+// stop tracking and close every recorded tab.
+func trackPopups(ctx context.Context, browser *rod.Browser, page *rod.Page) *popupTracker {
+	ctx, cancel := context.WithCancel(ctx)
+	t := &popupTracker{browser: browser, stop: cancel, opened: make(chan struct{}, 1)}
+	go page.Context(ctx).EachEvent(func(e *proto.PageWindowOpen) {
+		t.mu.Lock()
+		t.requested = true
+		t.mu.Unlock()
+	})()
+	go browser.Context(ctx).EachEvent(func(e *proto.TargetTargetCreated) {
+		info := e.TargetInfo
+		if info.OpenerID != page.TargetID || info.Type != proto.TargetTargetInfoTypePage {
+			return
+		}
+		t.mu.Lock()
+		t.ids = append(t.ids, info.TargetID)
+		t.mu.Unlock()
+		select {
+		case t.opened <- struct{}{}:
+		default:
+		}
+	})()
+	return t
+}
+
+// latest attaches to the most recently opened popup, or returns nil when
+// none was opened. If the page asked to open a window whose target was not
+// recorded yet, it waits up to popupWait (bounded by ctx) for it; otherwise
+// it returns at once. Dialogs on the popup are answered according to
+// dialogs from before it loads.
+func (t *popupTracker) latest(ctx context.Context, dialogs string) *rod.Page {
+	t.mu.Lock()
+	n, requested := len(t.ids), t.requested
+	t.mu.Unlock()
+	if n == 0 {
+		if !requested {
+			return nil
+		}
+		wait, cancel := context.WithTimeout(ctx, popupWait)
+		defer cancel()
+		select {
+		case <-t.opened:
+		case <-wait.Done():
+			return nil
+		}
+	}
+	t.mu.Lock()
+	id := t.ids[len(t.ids)-1]
+	t.mu.Unlock()
+
+	popup, err := t.browser.PageFromTarget(id)
+	if err != nil {
+		slog.Warn("popup: failed to attach", "target", id, "error", err)
+		return nil
+	}
+	popup = popup.Context(ctx)
+	handleDialogs(ctx, popup, dialogs)
+	_ = popup.WaitLoad()
+	_ = popup.WaitDOMStable(300*time.Millisecond, 0.1)
+	return popup
+}
+
+// close stops tracking and closes every popup so tabs never leak out of
+// the page pool. It does not depend on the request context, which may
+// already be expired.
+func (t *popupTracker) close() {
+	t.stop()
+	t.mu.Lock()
+	ids := t.ids
+	t.ids = nil
+	t.mu.Unlock()
+	for _, id := range ids {
+		if _, err := (proto.TargetCloseTarget{TargetID: id}).Call(t.browser); err != nil {
+			slog.Debug("popup: close failed", "target", id, "error", err)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/use-agent/purify/models"
+	"github.com/use-agent/purify/selector"
+)
+
+// recording is the subset of the Chrome DevTools Recorder (Puppeteer
+// Replay) JSON export that Purify understands.
+type recording struct {
+	Title string          `json:"title"`
+	Steps []recordingStep `json:"steps"`
+}
+
+// recordingStep is a single Recorder step. Fields irrelevant to replay
+// (offsets, asserted events other than navigation) are ignored.
+type recordingStep struct {
+	Type      string            `json:"type"`
+	Target    string            `json:"target"`
+	Frame     []int             `json:"frame"`
+	Selectors []json.RawMessage `json:"selectors"`
+
+	// navigate
+	URL string `json:"url"`
+
+	// click / doubleClick
+	Button string `json:"button"`
+
+	// change
+	Value string `json:"value"`
+
+	// keyDown / keyUp
+	Key string `json:"key"`
+
+	// setViewport
+	Width             int     `json:"width"`
+	Height            int     `json:"height"`
+	DeviceScaleFactor float64 `json:"deviceScaleFactor"`
+	IsMobile          bool    `json:"isMobile"`
+	HasTouch          bool    `json:"hasTouch"`
+
+	// waitForElement
+	Operator string `json:"operator"`
+	Count    *int   `json:"count"`
+	Visible  *bool  `json:"visible"`
+
+	// waitForExpression
+	Expression string `json:"expression"`
+
+	// scroll
+	X int `json:"x"`
+	Y int `json:"y"`
+
+	AssertedEvents []struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"assertedEvents"`
+}
+
+// modifierKeys are held across recorded keyDown/keyUp pairs.
+var modifierKeys = map[string]bool{"Shift": true, "Control": true, "Alt": true, "Meta": true}
+
+// ParseRecording translates a Chrome DevTools Recorder JSON export into
+// actions. The leading navigate step is dropped when it points at startURL,
+// since the scraper has already loaded that page.
+//
+// Every unsupported step is reported in a single ErrCodeInvalidInput error
+// naming its index and type, so a recording is either replayed in full or
+// rejected up front.
+func ParseRecording(data []byte, startURL string) ([]models.Action, error) {
+	var rec recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, models.NewScrapeError(
+			models.ErrCodeInvalidInput,
+			"actions_recording: invalid JSON: "+err.Error(),
+			err,
+		)
+	}
+	if len(rec.Steps) == 0 {
+		return nil, models.NewScrapeError(
+			models.ErrCodeInvalidInput,
+			"actions_recording: recording has no steps",
+			nil,
+		)
+	}
+
+	var (
+		actions  []models.Action
+		problems []string
+		held     []string
+		navSeen  bool
+	)
+	fail := func(i int, step recordingStep, reason string) {
+		problems = append(problems, fmt.Sprintf("step %d (%s): %s", i, step.Type, reason))
+	}
+
+	for i, step := range rec.Steps {
+		if step.Target != "" && step.Target != "main" {
+			fail(i, step, "steps in other targets (popups, new tabs) are not supported")
+			continue
+		}
+		if len(step.Frame) > 0 {
+			fail(i, step, "steps inside iframes are not supported")
+			continue
+		}
+
+		switch step.Type {
+		case "setViewport":
+			actions = append(actions, models.Action{
+				Type: "set_viewport",
+				Viewport: &models.Viewport{
+					Width:             step.Width,
+					Height:            step.Height,
+					DeviceScaleFactor: step.DeviceScaleFactor,
+					Mobile:            step.IsMobile,
+					HasTouch:          step.HasTouch,
+				},
+			})
+
+		case "navigate":
+			first := !navSeen
+			navSeen = true
+			if first && sameRecordedURL(step.URL, startURL) {
+				continue
+			}
+			if step.URL == "" {
+				fail(i, step, "missing url")
+				continue
+			}
+			actions = append(actions, models.Action{Type: "navigate", URL: step.URL})
+
+		case "click", "doubleClick":
+			if step.Button != "" && step.Button != "primary" {
+				fail(i, step, fmt.Sprintf("%s button clicks are not supported", step.Button))
+				continue
+			}
+			sels, err := recordingSelectors(step.Selectors)
+			if err != nil {
+				fail(i, step, err.Error())
+				continue
+			}
+			clicks := 1
+			if step.Type == "doubleClick" {
+				clicks = 2
+			}
+			actions = append(actions, models.Action{
+				Type:              "click",
+				Selector:          sels[0],
+				FallbackSelectors: sels[1:],
+				ClickCount:        clicks,
+			})
+			actions = append(actions, navigationWait(step)...)
+
+		case "hover":
+			sels, err := recordingSelectors(step.Selectors)
+			if err != nil {
+				fail(i, step, err.Error())
+				continue
+			}
+			actions = append(actions, models.Action{
+				Type:              "hover",
+				Selector:          sels[0],
+				FallbackSelectors: sels[1:],
+			})
+
+		case "change":
+			sels, err := recordingSelectors(step.Selectors)
+			if err != nil {
+				fail(i, step, err.Error())
+				continue
+			}
+			actions = append(actions, models.Action{
+				Type:              "type",
+				Selector:          sels[0],
+				FallbackSelectors: sels[1:],
+				Text:              step.Value,
+			})
+
+		case "keyDown":
+			if modifierKeys[step.Key] {
+				held = append(held, step.Key)
+				continue
+			}
+			combo := strings.Join(append(append([]string(nil), held...), step.Key), "+")
+			if _, err := parseKeyCombo(combo); err != nil {
+				fail(i, step, err.Error())
+				continue
+			}
+			actions = append(actions, models.Action{Type: "press", Key: combo})
+			actions = append(actions, navigationWait(step)...)
+
+		case "keyUp":
+			// keyDown already produced a full press; only release modifiers.
+			// The Recorder attaches asserted navigations to the keyUp.
+			if !modifierKeys[step.Key] {
+				actions = append(actions, navigationWait(step)...)
+				continue
+			}
+			for j, k := range held {
+				if k == step.Key {
+					held = append(held[:j], held[j+1:]...)
+					break
+				}
+			}
+
+		case "waitForElement":
+			if step.Visible != nil && !*step.Visible {
+				fail(i, step, "waiting for elements to disappear is not supported")
+				continue
+			}
+			if (step.Operator != "" && step.Operator != ">=") || (step.Count != nil && *step.Count != 1) {
+				fail(i, step, "only the default count (>= 1) is supported")
+				continue
+			}
+			sels, err := recordingSelectors(step.Selectors)
+			if err != nil {
+				fail(i, step, err.Error())
+				continue
+			}
+			// Waits accept a single selector; use the most specific one.
+			actions = append(actions, models.Action{Type: "wait", Selector: sels[0]})
+
+		case "waitForExpression":
+			if step.Expression == "" {
+				fail(i, step, "missing expression")
+				continue
+			}
+			actions = append(actions, models.Action{Type: "wait", Expression: step.Expression})
+
+		case "scroll":
+			if len(step.Selectors) > 0 {
+				fail(i, step, "scrolling inside an element is not supported")
+				continue
+			}
+			actions = append(actions, models.Action{
+				Type: "execute_js",
+				Code: fmt.Sprintf("() => window.scrollTo(%d, %d)", step.X, step.Y),
+			})
+
+		default:
+			fail(i, step, "unsupported step type")
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, models.NewScrapeError(
+			models.ErrCodeInvalidInput,
+			"actions_recording: unsupported steps: "+strings.Join(problems, "; "),
+			nil,
+		)
+	}
+	return actions, nil
+}
+
+// navigationWait returns a wait action for steps whose recording asserts
+// a navigation, so later steps don't run against the previous page.
+func navigationWait(step recordingStep) []models.Action {
+	for _, ev := range step.AssertedEvents {
+		if ev.Type != "navigation" {
+			continue
+		}
+		expr := `document.readyState === "complete"`
+		if ev.URL != "" {
+			quoted, _ := json.Marshal(ev.URL)
+			expr = fmt.Sprintf(`location.href === %s && %s`, quoted, expr)
+		}
+		return []models.Action{{Type: "wait", Expression: expr}}
+	}
+	return nil
+}
+
+// sameRecordedURL compares URLs ignoring the fragment and the difference
+// between an empty path and "/".
+func sameRecordedURL(a, b string) bool {
+	norm := func(raw string) string {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return raw
+		}
+		u.Fragment = ""
+		if u.Path == "" {
+			u.Path = "/"
+		}
+		return u.String()
+	}
+	return norm(a) == norm(b)
+}
+
+// recordingSelectors converts a Recorder selector list into Purify selectors
+// in the recorded order of preference. Each entry is either a string or an
+// array of strings (one per shadow root / frame hop). Entries that cannot be
+// expressed are skipped; an error is returned only if none remain.
+func recordingSelectors(raw []json.RawMessage) ([]string, error) {
+	var out []string
+	seen := make(map[string]bool)
+	for _, entry := range raw {
+		var parts []string
+		if err := json.Unmarshal(entry, &parts); err != nil {
+			var single string
+			if err := json.Unmarshal(entry, &single); err != nil {
+				continue
+			}
+			parts = []string{single}
+		}
+
+		converted := make([]string, 0, len(parts))
+		for _, part := range parts {
+			sel, ok := recordingSelector(part)
+			if !ok {
+				converted = nil
+				break
+			}
+			converted = append(converted, sel)
+		}
+		if len(converted) == 0 {
+			continue
+		}
+		// Multi-part selectors descend through shadow roots; chaining them
+		// matches when the parts are in the light DOM.
+		chain := strings.Join(converted, " >> ")
+		if _, err := selector.Parse(chain); err != nil || seen[chain] {
+			continue
+		}
+		seen[chain] = true
+		out = append(out, chain)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no usable selector")
+	}
+	return out, nil
+}
+
+// recordingSelector maps one Puppeteer selector to the selector package
+// syntax: aria/Name[role="button"], xpath/..., text/... or CSS. pierce/...
+// selectors reach into shadow roots, which no engine here does, so they are
+// skipped and the next recorded selector is used instead.
+func recordingSelector(s string) (string, bool) {
+	switch {
+	case strings.HasPrefix(s, "aria/"):
+		name, role := strings.TrimPrefix(s, "aria/"), "*"
+		if open := strings.LastIndex(name, "[role="); open >= 0 && strings.HasSuffix(name, "]") {
+			role = strings.Trim(name[open+len("[role="):len(name)-1], `"'`)
+			name = name[:open]
+		}
+		if name == "" {
+			return "role=" + role, role != "*"
+		}
+		return fmt.Sprintf(`role=%s[name="%s"]`, role, name), true
+	case strings.HasPrefix(s, "xpath/"):
+		return "xpath=" + strings.TrimPrefix(s, "xpath/"), true
+	case strings.HasPrefix(s, "text/"):
+		return "text=" + strings.TrimPrefix(s, "text/"), true
+	case strings.HasPrefix(s, "pierce/"):
+		return "", false
+	default:
+		return s, s != ""
+	}
+}
@@ -1,5 +1,7 @@
 package scraper
 
+import "github.com/use-agent/purify/models"
+
 // ScrapeResult holds the output of a single scrape operation.
 type ScrapeResult struct {
 	// RawHTML is the raw page HTML.
@@ -20,4 +22,49 @@ type ScrapeResult struct {
 	// FetchMethod records how the page was fetched: "http" or "browser".
 	// Used by the extract handler for metadata.
 	FetchMethod string
+
+	// Pages holds the pages captured after the first one when pagination
+	// is enabled, in the order they were followed.
+	Pages []PageResult
+
+	// Downloads holds files downloaded during the session when downloads
+	// are enabled.
+	Downloads []DownloadResult
+
+	// Accessibility is the rendered accessibility tree, captured when the
+	// output format is "accessibility".
+	Accessibility string
+
+	// InteractiveElements lists the final page's interactive elements when
+	// the request enabled them.
+	InteractiveElements []models.InteractiveElement
+
+	// BlockedRequests is the number of requests failed by the hijack router.
+	BlockedRequests int
+
+	// ConsentManager is the consent management platform answered by the
+	// consent option, if any.
+	ConsentManager string
+}
+
+// PageResult is a single additional page captured while following pagination.
+type PageResult struct {
+	RawHTML  string
+	Title    string
+	FinalURL string
+}
+
+// DownloadResult is a file downloaded by the browser during a scrape.
+type DownloadResult struct {
+	Filename string
+	URL      string
+	MIMEType string
+	Size     int64
+
+	// Data holds the file contents; nil when the file exceeded the size cap
+	// or did not complete.
+	Data []byte
+
+	// Error explains why Data is missing.
+	Error string
 }
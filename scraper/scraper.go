@@ -1,6 +1,7 @@
 package scraper
 
 import (
+	"context"
 	"log/slog"
 	"net/url"
 	"sync/atomic"
@@ -9,6 +10,7 @@ import (
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/launcher/flags"
+	"github.com/use-agent/purify/adblock"
 	"github.com/use-agent/purify/config"
 	"github.com/use-agent/purify/engine"
 	"github.com/use-agent/purify/models"
@@ -27,6 +29,7 @@ type Scraper struct {
 	startTime   time.Time
 	dispatcher  *engine.Dispatcher
 	relay       *proxy.Relay
+	filters     *adblock.Engine
 }
 
 // NewScraper launches a headless browser and initialises the reusable page pool.
@@ -135,6 +138,11 @@ func (s *Scraper) SetDispatcher(d *engine.Dispatcher) {
 	s.dispatcher = d
 }
 
+// SetFilterEngine installs the filter lists used by block_ads.
+func (s *Scraper) SetFilterEngine(e *adblock.Engine) {
+	s.filters = e
+}
+
 // Stats returns a snapshot of the pool's current state.
 func (s *Scraper) Stats() models.PoolStats {
 	return models.PoolStats{
@@ -143,6 +151,21 @@ func (s *Scraper) Stats() models.PoolStats {
 	}
 }
 
+// takePoolSlot reserves a page pool slot for a tab opened outside the pool,
+// so such tabs still count toward MaxPages. A pooled tab held by the slot
+// is closed. The returned func gives the slot back, empty.
+func (s *Scraper) takePoolSlot(ctx context.Context) (release func(), err error) {
+	select {
+	case page := <-s.pagePool:
+		if page != nil {
+			_ = page.Close()
+		}
+		return func() { s.pagePool.Put(nil) }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // Close drains the page pool and kills the browser process.
 // Call this on graceful shutdown to prevent zombie Chrome processes.
 func (s *Scraper) Close() {
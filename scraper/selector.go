@@ -0,0 +1,196 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/use-agent/purify/selector"
+)
+
+// resolveSelectorJS evaluates a parsed selector chain inside the page. It
+// mirrors selector.QueryAll so the same selector string behaves identically
+// before rendering (actions) and after it (cleaner filters). With all=false
+// it returns the first match or null; with all=true, the array of matches.
+const resolveSelectorJS = `(steps, roles, all) => {
+	const SKIP = new Set(['SCRIPT', 'STYLE', 'NOSCRIPT', 'TEMPLATE', 'HEAD', 'TITLE']);
+	const fold = (s) => (s || '').replace(/\s+/g, ' ').trim();
+	const inOrder = (set) => {
+		const out = [...set];
+		out.sort((a, b) => a === b ? 0 : (a.compareDocumentPosition(b) & Node.DOCUMENT_POSITION_FOLLOWING) ? -1 : 1);
+		return out;
+	};
+	const elements = (scope) => scope.querySelectorAll('*');
+
+	const roleOf = (el) => {
+		const explicit = fold(el.getAttribute('role')).split(' ')[0];
+		if (explicit) return explicit;
+		for (const r of roles) {
+			if (el.matches(r.selector)) return r.role;
+		}
+		return '';
+	};
+	const nameOf = (el) => {
+		let v = fold(el.getAttribute('aria-label'));
+		if (v) return v;
+		const ids = fold(el.getAttribute('aria-labelledby'));
+		if (ids) {
+			v = fold(ids.split(' ').map(id => {
+				const ref = document.getElementById(id);
+				return ref ? ref.textContent : '';
+			}).join(' '));
+			if (v) return v;
+		}
+		const tag = el.tagName;
+		if (tag === 'INPUT' || tag === 'SELECT' || tag === 'TEXTAREA') {
+			if (el.id) {
+				const label = document.querySelector('label[for="' + CSS.escape(el.id) + '"]');
+				if (label && (v = fold(label.textContent))) return v;
+			}
+			const wrap = el.closest('label');
+			if (wrap && (v = fold(wrap.textContent))) return v;
+			if (tag === 'INPUT') {
+				const type = (el.getAttribute('type') || '').toLowerCase();
+				if ((type === 'submit' || type === 'button' || type === 'reset') && (v = fold(el.getAttribute('value')))) return v;
+				if (type === 'image' && (v = fold(el.getAttribute('alt')))) return v;
+			}
+			if ((v = fold(el.getAttribute('placeholder')))) return v;
+			return fold(el.getAttribute('title'));
+		}
+		if (tag === 'IMG') {
+			return fold(el.getAttribute('alt')) || fold(el.getAttribute('title'));
+		}
+		return fold(el.textContent) || fold(el.getAttribute('title'));
+	};
+
+	const visibleText = (el) => {
+		if (SKIP.has(el.tagName) || el.hasAttribute('hidden')) return '';
+		return el.innerText !== undefined ? el.innerText : el.textContent;
+	};
+	const matchText = (scope, step) => {
+		const want = step.exact ? fold(step.value) : fold(step.value).toLowerCase();
+		const test = (el) => {
+			const text = fold(visibleText(el));
+			return step.exact ? text === want : text.toLowerCase().includes(want);
+		};
+		const out = [];
+		const walk = (el) => {
+			if (SKIP.has(el.tagName) || el.hasAttribute('hidden')) return false;
+			let childMatched = false;
+			for (const c of el.children) {
+				if (walk(c)) childMatched = true;
+			}
+			if (childMatched) return true;
+			if (test(el)) { out.push(el); return true; }
+			return false;
+		};
+		for (const c of scope.children) walk(c);
+		return out;
+	};
+
+	const evalStep = (scope, step, nested) => {
+		switch (step.engine) {
+		case 'css':
+			return [...scope.querySelectorAll(step.value)];
+		case 'xpath': {
+			let expr = step.value;
+			if (nested && expr.startsWith('/')) expr = '.' + expr;
+			const snap = document.evaluate(expr, scope, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+			const out = [];
+			for (let i = 0; i < snap.snapshotLength; i++) {
+				const n = snap.snapshotItem(i);
+				if (n.nodeType === Node.ELEMENT_NODE) out.push(n);
+			}
+			return out;
+		}
+		case 'text':
+			return matchText(scope, step);
+		case 'role': {
+			const want = fold(step.name || '').toLowerCase();
+			return [...elements(scope)].filter(el => {
+				const role = roleOf(el);
+				if (!role || (step.role !== '*' && role !== step.role)) return false;
+				return !want || nameOf(el).toLowerCase() === want;
+			});
+		}
+		}
+		return [];
+	};
+
+	let scopes = [document];
+	steps.forEach((step, i) => {
+		if (step.engine === 'nth') {
+			let idx = step.index || 0;
+			if (idx < 0) idx += scopes.length;
+			scopes = idx >= 0 && idx < scopes.length ? [scopes[idx]] : [];
+			return;
+		}
+		const set = new Set();
+		for (const scope of scopes) {
+			for (const el of evalStep(scope, step, i > 0)) set.add(el);
+		}
+		scopes = inOrder(set);
+	});
+	if (all) return scopes;
+	return scopes.length > 0 ? scopes[0] : null;
+}`
+
+// findElement resolves the first of the given selectors that matches an
+// element, waiting for one to appear like p.Element does. Plain CSS
+// selectors use rod's native lookup; other engines run resolveSelectorJS.
+func findElement(p *rod.Page, raws ...string) (*rod.Element, error) {
+	sels := make([]*selector.Selector, 0, len(raws))
+	for _, raw := range raws {
+		sel, err := selector.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	if len(sels) == 1 {
+		return queryElement(p, sels[0])
+	}
+
+	// Several candidates: poll them all without rod's per-query retry, so
+	// a missing first candidate doesn't consume the whole action timeout.
+	quick := p.Sleeper(rod.NotFoundSleeper)
+	for {
+		for _, sel := range sels {
+			el, err := queryElement(quick, sel)
+			if err == nil {
+				return el, nil
+			}
+			if !errors.Is(err, &rod.ElementNotFoundError{}) {
+				return nil, err
+			}
+		}
+		select {
+		case <-p.GetContext().Done():
+			return nil, p.GetContext().Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// queryElement looks up a single parsed selector.
+func queryElement(p *rod.Page, sel *selector.Selector) (*rod.Element, error) {
+	if css, ok := sel.CSS(); ok {
+		return p.Element(css)
+	}
+	return p.ElementByJS(rod.Eval(resolveSelectorJS, sel.Steps, selector.ImplicitRoles, false))
+}
+
+// waitForSelector blocks until at least one element matches the selector
+// or the page context expires.
+func waitForSelector(p *rod.Page, raw string) error {
+	sel, err := selector.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if css, ok := sel.CSS(); ok {
+		return p.WaitElementsMoreThan(css, 0)
+	}
+	js := fmt.Sprintf(`(steps, roles) => !!(%s)(steps, roles, false)`, resolveSelectorJS)
+	return p.Wait(rod.Eval(js, sel.Steps, selector.ImplicitRoles))
+}
@@ -0,0 +1,115 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/use-agent/purify/selector"
+	"golang.org/x/net/html"
+)
+
+const agreementDoc = `<html><head><title>Sign in</title></head><body>
+<nav id="menu" data-id="nav">
+  <ul data-id="menu-list">
+    <li data-id="m1"><a href="/" data-id="home">Home</a></li>
+    <li data-id="m2"><a href="/about" data-id="about">About us</a></li>
+  </ul>
+</nav>
+<main data-id="main">
+  <h1 data-id="h1">Welcome</h1>
+  <ul data-id="list">
+    <li data-id="i1">First</li>
+    <li data-id="i2">Second <b data-id="b2">item</b></li>
+    <li data-id="i3">Third</li>
+  </ul>
+  <form data-id="form">
+    <label for="email" data-id="label">E-mail</label>
+    <input id="email" type="email" data-id="email">
+    <label data-id="wrap">Remember me <input type="checkbox" data-id="remember"></label>
+    <input type="submit" value="Sign in" data-id="submit">
+    <button aria-label="Close dialog" data-id="close">x</button>
+    <div role="button" data-id="fake">Sign in</div>
+    <span id="lbl" data-id="lbl">Search the site</span>
+    <input type="search" aria-labelledby="lbl" data-id="search">
+  </form>
+  <p data-id="p-hidden" hidden>Sign in</p>
+  <img src="a.png" alt="" data-id="spacer">
+  <img src="b.png" alt="Logo" data-id="logo">
+</main>
+</body></html>`
+
+// TestResolveSelectorJS_AgreesWithQueryAll checks that resolveSelectorJS
+// and selector.QueryAll match the same elements. It needs a local Chrome.
+func TestResolveSelectorJS_AgreesWithQueryAll(t *testing.T) {
+	bin, found := launcher.LookPath()
+	if !found {
+		t.Skip("no local Chrome")
+	}
+	u, err := launcher.New().Bin(bin).Headless(true).Launch()
+	if err != nil {
+		t.Skipf("launch Chrome: %v", err)
+	}
+	browser := rod.New().ControlURL(u)
+	if err := browser.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer browser.Close()
+	page := browser.MustPage("")
+	if err := page.SetDocumentContent(agreementDoc); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := html.Parse(strings.NewReader(agreementDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	js := fmt.Sprintf(`(steps, roles) =>
+		(%s)(steps, roles, true).map(el => el.getAttribute('data-id')).join(' ')`, resolveSelectorJS)
+	for _, raw := range []string{
+		"li",
+		"main li",
+		"//main//li[2]",
+		"text=sign",
+		"text=item",
+		`text="Second item"`,
+		"role=link",
+		"role=button",
+		`role=button[name="sign in"]`,
+		`role=textbox[name="E-mail"]`,
+		`role=checkbox[name="Remember me"]`,
+		`role=searchbox[name="Search the site"]`,
+		"role=presentation",
+		`role=*[name="Welcome"]`,
+		"role=list >> role=listitem >> nth=1",
+		"li >> nth=-1",
+		"main >> //li",
+		"ul >> text=third",
+	} {
+		sel, err := selector.Parse(raw)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", raw, err)
+		}
+
+		var want []string
+		for _, n := range sel.QueryAll(doc) {
+			for _, a := range n.Attr {
+				if a.Key == "data-id" {
+					want = append(want, a.Val)
+				}
+			}
+		}
+
+		res, err := page.Eval(js, sel.Steps, selector.ImplicitRoles)
+		if err != nil {
+			t.Errorf("resolveSelectorJS(%q): %v", raw, err)
+			continue
+		}
+		if got := res.Value.Str(); got != strings.Join(want, " ") {
+			t.Errorf("%q: browser matched %q, QueryAll matched %q", raw, got, strings.Join(want, " "))
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/go-rod/stealth"
+	"github.com/use-agent/purify/models"
+)
+
+// SessionOptions configures an interactive browsing session.
+type SessionOptions struct {
+	// Stealth injects the stealth script into every document.
+	Stealth bool
+
+	// Dialogs answers JavaScript dialogs: "accept" or "dismiss" (default).
+	Dialogs string
+}
+
+// SessionEvent is something that happened in a session's page outside of
+// a command: a main-frame navigation, a console message or a dialog.
+type SessionEvent struct {
+	Type    string // "navigated", "console" or "dialog"
+	URL     string // navigated
+	Level   string // console: "log", "warning", "error", ...
+	Message string // console text or dialog message
+	Dialog  string // dialog type: "alert", "confirm", "prompt", "beforeunload"
+}
+
+// PageState describes the page a session is showing.
+type PageState struct {
+	URL        string
+	Title      string
+	StatusCode int
+}
+
+// Session holds a pooled tab open across several commands so a client can
+// browse step by step. Methods must not be called concurrently; Close
+// returns the tab to the pool.
+type Session struct {
+	s      *Scraper
+	page   *rod.Page
+	stop   context.CancelFunc
+	popups *popupTracker
+	once   sync.Once
+
+	// viewport records that a set_viewport action overrode the metrics.
+	viewport bool
+}
+
+// OpenSession borrows a tab from the page pool for interactive browsing.
+// onEvent receives page events from a background goroutine until the
+// session is closed.
+func (s *Scraper) OpenSession(opts SessionOptions, onEvent func(SessionEvent)) (*Session, error) {
+	page, err := s.pagePool.Get(func() (*rod.Page, error) {
+		return s.browser.Page(proto.TargetCreateTarget{})
+	})
+	if err != nil {
+		return nil, models.NewScrapeError(
+			models.ErrCodeBrowserCrash,
+			"failed to acquire page from pool",
+			err,
+		)
+	}
+	s.activePages.Add(1)
+
+	if opts.Stealth {
+		if _, evalErr := page.EvalOnNewDocument(stealth.JS); evalErr != nil {
+			slog.Warn("session: stealth injection failed", "error", evalErr)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	accept := opts.Dialogs == "accept"
+	go page.Context(ctx).EachEvent(
+		func(e *proto.PageFrameNavigated) {
+			if e.Frame.ParentID == "" {
+				onEvent(SessionEvent{Type: "navigated", URL: e.Frame.URL})
+			}
+		},
+		func(e *proto.RuntimeConsoleAPICalled) {
+			onEvent(SessionEvent{Type: "console", Level: string(e.Type), Message: consoleText(e.Args)})
+		},
+		func(e *proto.PageJavascriptDialogOpening) {
+			onEvent(SessionEvent{Type: "dialog", Dialog: string(e.Type), Message: e.Message})
+			_ = proto.PageHandleJavaScriptDialog{Accept: accept}.Call(page)
+		},
+	)()
+
+	return &Session{
+		s:      s,
+		page:   page,
+		stop:   cancel,
+		popups: trackPopups(ctx, s.browser, page),
+	}, nil
+}
+
+// consoleText joins console arguments the way DevTools prints them.
+func consoleText(args []*proto.RuntimeRemoteObject) string {
+	parts := make([]string, 0, len(args))
+	for _, a := range args {
+		switch {
+		case a.Type == proto.RuntimeRemoteObjectTypeString:
+			parts = append(parts, a.Value.Str())
+		case a.Description != "":
+			parts = append(parts, a.Description)
+		case !a.Value.Nil():
+			parts = append(parts, a.Value.JSON("", ""))
+		default:
+			parts = append(parts, string(a.Type))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Navigate loads rawURL and waits for the DOM to settle.
+func (ss *Session) Navigate(ctx context.Context, rawURL string) (PageState, error) {
+	p := ss.page.Context(ctx)
+	if err := p.Navigate(rawURL); err != nil {
+		return PageState{}, categorizeError(err, "navigation to target URL failed")
+	}
+	if err := p.WaitDOMStable(300*time.Millisecond, 0.1); err != nil {
+		slog.Debug("session: WaitDOMStable did not converge", "error", err)
+	}
+	state := ss.State(ctx)
+	if res, err := p.Eval(navigationStatusJS); err == nil {
+		state.StatusCode = res.Value.Int()
+	}
+	return state, nil
+}
+
+// Run executes actions on the page in order.
+func (ss *Session) Run(ctx context.Context, actions []models.Action) (PageState, error) {
+	if hasActionType(actions, "set_viewport") {
+		ss.viewport = true
+	}
+	if err := executeActions(ctx, ss.page, actions); err != nil {
+		return PageState{}, err
+	}
+	return ss.State(ctx), nil
+}
+
+// State returns the current URL and title (best-effort).
+func (ss *Session) State(ctx context.Context) PageState {
+	p := ss.page.Context(ctx)
+	return PageState{
+		URL:   evalStringOrEmpty(p, `() => window.location.href`),
+		Title: evalStringOrEmpty(p, `() => document.title`),
+	}
+}
+
+// HTML returns the rendered HTML of the page.
+func (ss *Session) HTML(ctx context.Context) (string, error) {
+	html, err := captureHTML(ss.page.Context(ctx), false)
+	if err != nil {
+		return "", categorizeError(err, "failed to extract page HTML")
+	}
+	return html, nil
+}
+
+// Accessibility returns the page's accessibility tree (see renderAXTree).
+func (ss *Session) Accessibility(ctx context.Context) (string, error) {
+	tree, err := accessibilitySnapshot(ss.page.Context(ctx))
+	if err != nil {
+		return "", categorizeError(err, "failed to capture accessibility tree")
+	}
+	return tree, nil
+}
+
+// InteractiveElements lists the page's interactive elements, assigning refs
+// that later actions in the session can target.
+func (ss *Session) InteractiveElements(ctx context.Context) []models.InteractiveElement {
+	return interactiveElements(ss.page.Context(ctx))
+}
+
+// Screenshot captures the viewport, or the whole page when fullPage is set,
+// as "png" or "jpeg" (quality 0-100, jpeg only).
+func (ss *Session) Screenshot(ctx context.Context, fullPage bool, format string, quality int) ([]byte, error) {
+	req := &proto.PageCaptureScreenshot{Format: proto.PageCaptureScreenshotFormatPng}
+	if format == "jpeg" {
+		req.Format = proto.PageCaptureScreenshotFormatJpeg
+		if quality > 0 {
+			req.Quality = &quality
+		}
+	}
+	img, err := ss.page.Context(ctx).Screenshot(fullPage, req)
+	if err != nil {
+		return nil, categorizeError(err, fmt.Sprintf("failed to capture %s screenshot", req.Format))
+	}
+	return img, nil
+}
+
+// Close stops event delivery, closes popups and returns the tab to the
+// pool. Safe to call more than once.
+func (ss *Session) Close() {
+	ss.once.Do(func() {
+		ss.stop()
+		ss.popups.close()
+		if ss.viewport {
+			_ = proto.EmulationClearDeviceMetricsOverride{}.Call(ss.page)
+			_ = proto.EmulationSetTouchEmulationEnabled{Enabled: false}.Call(ss.page)
+		}
+		if err := ss.page.Navigate("about:blank"); err != nil {
+			slog.Warn("session cleanup: failed to navigate to about:blank", "error", err)
+		}
+		ss.s.pagePool.Put(ss.page)
+		ss.s.activePages.Add(-1)
+	})
+}
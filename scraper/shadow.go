@@ -0,0 +1,97 @@
+package scraper
+
+import (
+	"bytes"
+	"log/slog"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// captureHTML returns the page HTML, including shadow roots when
+// flattenShadow is set. Serialization errors fall back to page.HTML().
+func captureHTML(p *rod.Page, flattenShadow bool) (string, error) {
+	if flattenShadow {
+		out, err := serializeWithShadowRoots(p)
+		if err == nil {
+			return out, nil
+		}
+		slog.Warn("shadow DOM serialization failed, using light DOM only", "error", err)
+	}
+	return p.HTML()
+}
+
+// serializeWithShadowRoots returns the page HTML with every author shadow
+// root (open and closed) serialized as a declarative
+// <template shadowrootmode> inside its host. page.HTML() only serializes
+// light DOM; CDP's DOM.getDocument with pierce exposes closed roots too.
+// The cleaner expands the templates into light DOM before extraction.
+func serializeWithShadowRoots(p *rod.Page) (string, error) {
+	depth := -1
+	res, err := proto.DOMGetDocument{Depth: &depth, Pierce: true}.Call(p)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, convertDOMNode(res.Root)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// convertDOMNode converts a CDP node tree to an x/net/html tree. Frame
+// documents and user-agent shadow roots (built-in controls) are skipped.
+func convertDOMNode(n *proto.DOMNode) *html.Node {
+	var out *html.Node
+	switch n.NodeType {
+	case 9: // document
+		out = &html.Node{Type: html.DocumentNode}
+	case 10: // doctype
+		return &html.Node{Type: html.DoctypeNode, Data: n.NodeName}
+	case 3, 4: // text, CDATA
+		return &html.Node{Type: html.TextNode, Data: n.NodeValue}
+	case 8: // comment
+		return &html.Node{Type: html.CommentNode, Data: n.NodeValue}
+	case 1: // element
+		out = &html.Node{
+			Type:     html.ElementNode,
+			Data:     n.LocalName,
+			DataAtom: atom.Lookup([]byte(n.LocalName)),
+		}
+		for i := 0; i+1 < len(n.Attributes); i += 2 {
+			out.Attr = append(out.Attr, html.Attribute{Key: n.Attributes[i], Val: n.Attributes[i+1]})
+		}
+		for _, root := range n.ShadowRoots {
+			if root.ShadowRootType == proto.DOMShadowRootTypeUserAgent {
+				continue
+			}
+			tmpl := &html.Node{
+				Type:     html.ElementNode,
+				Data:     "template",
+				DataAtom: atom.Template,
+				Attr:     []html.Attribute{{Key: "shadowrootmode", Val: string(root.ShadowRootType)}},
+			}
+			appendDOMChildren(tmpl, root.Children)
+			out.AppendChild(tmpl)
+		}
+		if n.TemplateContent != nil {
+			appendDOMChildren(out, n.TemplateContent.Children)
+		}
+	default:
+		return nil
+	}
+	appendDOMChildren(out, n.Children)
+	return out
+}
+
+// appendDOMChildren converts and appends CDP child nodes to parent.
+func appendDOMChildren(parent *html.Node, children []*proto.DOMNode) {
+	for _, c := range children {
+		if child := convertDOMNode(c); child != nil {
+			parent.AppendChild(child)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package selector
+
+import (
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// textSkipTags are never matched by the text engine: their text is not
+// rendered.
+var textSkipTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+	"head":     true,
+	"title":    true,
+}
+
+// QueryAll evaluates the selector against a parsed HTML tree and returns the
+// matching elements in document order.
+func (s *Selector) QueryAll(root *html.Node) []*html.Node {
+	scopes := []*html.Node{root}
+	for i, step := range s.Steps {
+		if step.Engine == EngineNth {
+			scopes = pickNth(scopes, step.Index)
+			continue
+		}
+
+		seen := make(map[*html.Node]bool)
+		var next []*html.Node
+		for _, scope := range scopes {
+			for _, n := range evalStep(root, scope, step, i > 0) {
+				if !seen[n] {
+					seen[n] = true
+					next = append(next, n)
+				}
+			}
+		}
+		scopes = documentOrder(root, seen, len(next))
+		if len(scopes) == 0 {
+			return nil
+		}
+	}
+	return scopes
+}
+
+// evalStep returns the elements matched by one step within scope.
+func evalStep(root, scope *html.Node, step Step, nested bool) []*html.Node {
+	switch step.Engine {
+	case EngineCSS:
+		sel, err := cascadia.ParseGroup(step.Value)
+		if err != nil {
+			return nil
+		}
+		return cascadia.QueryAll(scope, sel)
+
+	case EngineXPath:
+		expr := step.Value
+		if nested && strings.HasPrefix(expr, "/") {
+			// Make absolute paths relative to the previous match, as the
+			// browser evaluator does via contextNode.
+			expr = "." + expr
+		}
+		nodes, err := htmlquery.QueryAll(scope, expr)
+		if err != nil {
+			return nil
+		}
+		out := nodes[:0]
+		for _, n := range nodes {
+			if n.Type == html.ElementNode {
+				out = append(out, n)
+			}
+		}
+		return out
+
+	case EngineText:
+		return matchText(scope, step)
+
+	case EngineRole:
+		var out []*html.Node
+		walkElements(scope, func(n *html.Node) bool {
+			if matchRole(root, n, step) {
+				out = append(out, n)
+			}
+			return true
+		})
+		return out
+	}
+	return nil
+}
+
+// matchRole reports whether n satisfies a role step.
+func matchRole(root, n *html.Node, step Step) bool {
+	role := Role(n)
+	if role == "" || (step.Role != "*" && role != step.Role) {
+		return false
+	}
+	if step.Name == "" {
+		return true
+	}
+	return strings.EqualFold(AccessibleName(root, n), fold(step.Name))
+}
+
+// matchText returns the deepest elements under scope whose visible text
+// matches the step. An element matches when its own folded text matches and
+// none of its element children match on their own, so a query returns the
+// innermost element carrying the text rather than every ancestor.
+func matchText(scope *html.Node, step Step) []*html.Node {
+	want := fold(step.Value)
+	if !step.Exact {
+		want = strings.ToLower(want)
+	}
+	matches := func(n *html.Node) bool {
+		text := fold(visibleText(n))
+		if step.Exact {
+			return text == want
+		}
+		return strings.Contains(strings.ToLower(text), want)
+	}
+
+	var out []*html.Node
+	var walk func(n *html.Node) bool
+	walk = func(n *html.Node) bool {
+		if textSkipTags[n.Data] || hasAttr(n, "hidden") {
+			return false
+		}
+		childMatched := false
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && walk(c) {
+				childMatched = true
+			}
+		}
+		if childMatched {
+			return true
+		}
+		if matches(n) {
+			out = append(out, n)
+			return true
+		}
+		return false
+	}
+	for c := scope.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			walk(c)
+		}
+	}
+	return out
+}
+
+// visibleText is textContent minus non-rendered subtrees.
+func visibleText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(c *html.Node) {
+		switch c.Type {
+		case html.TextNode:
+			b.WriteString(c.Data)
+			return
+		case html.ElementNode:
+			if textSkipTags[c.Data] || hasAttr(c, "hidden") {
+				return
+			}
+		}
+		for ch := c.FirstChild; ch != nil; ch = ch.NextSibling {
+			walk(ch)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// pickNth keeps the element at index i (negative counts from the end).
+func pickNth(nodes []*html.Node, i int) []*html.Node {
+	if i < 0 {
+		i += len(nodes)
+	}
+	if i < 0 || i >= len(nodes) {
+		return nil
+	}
+	return []*html.Node{nodes[i]}
+}
+
+// documentOrder returns the nodes in set ordered as they appear under root.
+func documentOrder(root *html.Node, set map[*html.Node]bool, n int) []*html.Node {
+	if n == 0 {
+		return nil
+	}
+	out := make([]*html.Node, 0, n)
+	if set[root] {
+		out = append(out, root)
+	}
+	walkElements(root, func(e *html.Node) bool {
+		if set[e] {
+			out = append(out, e)
+		}
+		return len(out) < n
+	})
+	return out
+}
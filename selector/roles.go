@@ -0,0 +1,231 @@
+package selector
+
+import (
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+// RoleRule maps elements matching a CSS selector to an implicit ARIA role.
+type RoleRule struct {
+	Selector string `json:"selector"`
+	Role     string `json:"role"`
+}
+
+// ImplicitRoles lists the implicit ARIA roles of common HTML elements.
+// Rules are checked in order and the first match wins; an explicit role
+// attribute always takes precedence. The list is also passed to the
+// in-browser evaluator so both sides agree on what "role=" means.
+var ImplicitRoles = []RoleRule{
+	{"a[href], area[href]", "link"},
+	{"button", "button"},
+	{"input[type=button], input[type=submit], input[type=reset], input[type=image]", "button"},
+	{"input[type=checkbox]", "checkbox"},
+	{"input[type=radio]", "radio"},
+	{"input[type=range]", "slider"},
+	{"input[type=number]", "spinbutton"},
+	{"input[type=search]", "searchbox"},
+	{"input:not([type]), input[type=text], input[type=email], input[type=tel], input[type=url], textarea", "textbox"},
+	{"select[multiple]", "listbox"},
+	{"select", "combobox"},
+	{"option", "option"},
+	{"h1, h2, h3, h4, h5, h6", "heading"},
+	{"img[alt='']", "presentation"},
+	{"img", "img"},
+	{"nav", "navigation"},
+	{"main", "main"},
+	{"header", "banner"},
+	{"footer", "contentinfo"},
+	{"aside", "complementary"},
+	{"form", "form"},
+	{"article", "article"},
+	{"dialog", "dialog"},
+	{"ul, ol", "list"},
+	{"li", "listitem"},
+	{"table", "table"},
+	{"tr", "row"},
+	{"th", "columnheader"},
+	{"td", "cell"},
+	{"progress", "progressbar"},
+	{"hr", "separator"},
+}
+
+// roleMatcher is an ImplicitRoles entry with its selector compiled.
+type roleMatcher struct {
+	sel  cascadia.Selector
+	role string
+}
+
+// compiledRoles holds ImplicitRoles with pre-compiled selectors.
+var compiledRoles = func() []roleMatcher {
+	out := make([]roleMatcher, 0, len(ImplicitRoles))
+	for _, r := range ImplicitRoles {
+		out = append(out, roleMatcher{cascadia.MustCompile(r.Selector), r.Role})
+	}
+	return out
+}()
+
+// Role returns the ARIA role of an element: the first token of its role
+// attribute, or its implicit role. Returns "" for elements without a role.
+func Role(n *html.Node) string {
+	if n.Type != html.ElementNode {
+		return ""
+	}
+	if explicit := strings.Fields(attr(n, "role")); len(explicit) > 0 {
+		return explicit[0]
+	}
+	for _, r := range compiledRoles {
+		if r.sel.Match(n) {
+			return r.role
+		}
+	}
+	return ""
+}
+
+// AccessibleName approximates the accessible name of an element using the
+// same precedence as the in-browser evaluator: aria-label, aria-labelledby,
+// form-control labels, alt text, then text content, then title.
+func AccessibleName(root, n *html.Node) string {
+	if v := fold(attr(n, "aria-label")); v != "" {
+		return v
+	}
+	if ids := strings.Fields(attr(n, "aria-labelledby")); len(ids) > 0 {
+		var parts []string
+		for _, id := range ids {
+			if ref := findByID(root, id); ref != nil {
+				parts = append(parts, textContent(ref))
+			}
+		}
+		if v := fold(strings.Join(parts, " ")); v != "" {
+			return v
+		}
+	}
+
+	switch n.Data {
+	case "input", "select", "textarea":
+		if id := attr(n, "id"); id != "" {
+			if label := findLabelFor(root, id); label != nil {
+				if v := fold(textContent(label)); v != "" {
+					return v
+				}
+			}
+		}
+		for p := n.Parent; p != nil; p = p.Parent {
+			if p.Type == html.ElementNode && p.Data == "label" {
+				if v := fold(textContent(p)); v != "" {
+					return v
+				}
+				break
+			}
+		}
+		if n.Data == "input" {
+			switch strings.ToLower(attr(n, "type")) {
+			case "submit", "button", "reset":
+				if v := fold(attr(n, "value")); v != "" {
+					return v
+				}
+			case "image":
+				if v := fold(attr(n, "alt")); v != "" {
+					return v
+				}
+			}
+		}
+		if v := fold(attr(n, "placeholder")); v != "" {
+			return v
+		}
+		return fold(attr(n, "title"))
+
+	case "img":
+		if v := fold(attr(n, "alt")); v != "" {
+			return v
+		}
+		return fold(attr(n, "title"))
+	}
+
+	if v := fold(textContent(n)); v != "" {
+		return v
+	}
+	return fold(attr(n, "title"))
+}
+
+// attr returns the value of an attribute, or "" when absent.
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasAttr reports whether the attribute is present.
+func hasAttr(n *html.Node, name string) bool {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fold collapses runs of whitespace and trims the result.
+func fold(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// textContent concatenates all descendant text, like DOM textContent.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(c *html.Node) {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+		for ch := c.FirstChild; ch != nil; ch = ch.NextSibling {
+			walk(ch)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// findByID returns the first element with the given id.
+func findByID(root *html.Node, id string) *html.Node {
+	var found *html.Node
+	walkElements(root, func(n *html.Node) bool {
+		if attr(n, "id") == id {
+			found = n
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// findLabelFor returns the <label for=id> element, if any.
+func findLabelFor(root *html.Node, id string) *html.Node {
+	var found *html.Node
+	walkElements(root, func(n *html.Node) bool {
+		if n.Data == "label" && attr(n, "for") == id {
+			found = n
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// walkElements visits element descendants of root in document order until
+// fn returns false.
+func walkElements(root *html.Node, fn func(*html.Node) bool) bool {
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && !fn(c) {
+			return false
+		}
+		if !walkElements(c, fn) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+// Package selector parses the selector strings accepted by browser actions
+// and content filters, so one selector works both before rendering (in the
+// browser) and after it (on the captured HTML).
+//
+// A selector is one or more steps joined by " >> ". Each step is evaluated
+// inside the matches of the previous one. A step is either plain CSS or
+// carries an engine prefix:
+//
+//	css=article .title              CSS (the default when no prefix is given)
+//	xpath=//button[@type="submit"]  XPath 1.0 (also inferred from a leading "//")
+//	text=Sign in                    visible text, case-insensitive substring
+//	text="Sign in"                  visible text, exact after whitespace folding
+//	role=button[name="Submit"]      ARIA role (explicit or implicit) plus name
+//	nth=0                           keep only the Nth match (negative counts from the end)
+//
+// Example: `role=list >> role=listitem >> nth=2`.
+package selector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/xpath"
+)
+
+// Engine names.
+const (
+	EngineCSS   = "css"
+	EngineXPath = "xpath"
+	EngineText  = "text"
+	EngineRole  = "role"
+	EngineNth   = "nth"
+)
+
+// chainSeparator joins steps in a selector chain.
+const chainSeparator = " >> "
+
+// Step is one parsed step of a selector chain. It is JSON-serialisable so
+// the same parsed form can be handed to the in-browser evaluator.
+type Step struct {
+	Engine string `json:"engine"`
+
+	// Value is the CSS selector, XPath expression or text query.
+	Value string `json:"value,omitempty"`
+
+	// Exact is set for quoted text queries.
+	Exact bool `json:"exact,omitempty"`
+
+	// Role and Name are set for the role engine. Role "*" matches any
+	// element that has a role; an empty Name matches any name.
+	Role string `json:"role,omitempty"`
+	Name string `json:"name,omitempty"`
+
+	// Index is the position selected by the nth engine.
+	Index int `json:"index,omitempty"`
+}
+
+// Selector is a parsed selector chain.
+type Selector struct {
+	Raw   string
+	Steps []Step
+}
+
+// Parse parses a selector string. Plain CSS selectors (no prefix, no chain)
+// parse into a single css step, so existing selectors keep working.
+func Parse(raw string) (*Selector, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("selector: empty selector")
+	}
+
+	parts := strings.Split(raw, chainSeparator)
+	sel := &Selector{Raw: raw, Steps: make([]Step, 0, len(parts))}
+	for i, part := range parts {
+		step, err := parseStep(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if step.Engine == EngineNth && i == 0 {
+			return nil, fmt.Errorf("selector: nth= cannot be the first step in %q", raw)
+		}
+		sel.Steps = append(sel.Steps, step)
+	}
+	return sel, nil
+}
+
+// CSS returns the CSS selector when the whole chain is a single CSS step,
+// letting callers use native CSS querying as a fast path.
+func (s *Selector) CSS() (string, bool) {
+	if len(s.Steps) == 1 && s.Steps[0].Engine == EngineCSS {
+		return s.Steps[0].Value, true
+	}
+	return "", false
+}
+
+// parseStep parses a single step and validates its engine-specific syntax.
+func parseStep(part string) (Step, error) {
+	if part == "" {
+		return Step{}, fmt.Errorf("selector: empty step")
+	}
+
+	engine, value := EngineCSS, part
+	if idx := strings.IndexByte(part, '='); idx > 0 {
+		switch name := part[:idx]; name {
+		case EngineCSS, EngineXPath, EngineText, EngineRole, EngineNth:
+			engine, value = name, strings.TrimSpace(part[idx+1:])
+		}
+	}
+	if engine == EngineCSS && (strings.HasPrefix(part, "//") || strings.HasPrefix(part, "(//")) {
+		engine = EngineXPath
+	}
+	if value == "" {
+		return Step{}, fmt.Errorf("selector: %s= requires a value", engine)
+	}
+
+	switch engine {
+	case EngineCSS:
+		if _, err := cascadia.ParseGroup(value); err != nil {
+			return Step{}, fmt.Errorf("selector: invalid CSS %q: %w", value, err)
+		}
+		return Step{Engine: engine, Value: value}, nil
+
+	case EngineXPath:
+		if _, err := xpath.Compile(value); err != nil {
+			return Step{}, fmt.Errorf("selector: invalid XPath %q: %w", value, err)
+		}
+		return Step{Engine: engine, Value: value}, nil
+
+	case EngineText:
+		if q, ok := unquote(value); ok {
+			return Step{Engine: engine, Value: q, Exact: true}, nil
+		}
+		return Step{Engine: engine, Value: value}, nil
+
+	case EngineRole:
+		return parseRole(value)
+
+	default: // EngineNth
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return Step{}, fmt.Errorf("selector: invalid nth index %q", value)
+		}
+		return Step{Engine: engine, Index: n}, nil
+	}
+}
+
+// parseRole parses `button`, `button[name="Submit"]` or `*[name='Close']`.
+func parseRole(value string) (Step, error) {
+	step := Step{Engine: EngineRole, Role: value}
+	open := strings.IndexByte(value, '[')
+	if open < 0 {
+		return step, nil
+	}
+	if !strings.HasSuffix(value, "]") {
+		return Step{}, fmt.Errorf("selector: unterminated role attribute in %q", value)
+	}
+
+	step.Role = strings.TrimSpace(value[:open])
+	attr := strings.TrimSpace(value[open+1 : len(value)-1])
+	name, val, found := strings.Cut(attr, "=")
+	if !found || strings.TrimSpace(name) != "name" {
+		return Step{}, fmt.Errorf("selector: unsupported role attribute %q (only name=\"...\")", attr)
+	}
+	q, ok := unquote(strings.TrimSpace(val))
+	if !ok {
+		return Step{}, fmt.Errorf("selector: role name must be quoted in %q", value)
+	}
+	step.Name = q
+	if step.Role == "" {
+		step.Role = "*"
+	}
+	return step, nil
+}
+
+// unquote strips matching single or double quotes.
+func unquote(s string) (string, bool) {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], true
+	}
+	return s, false
+}
@@ -0,0 +1,198 @@
+package selector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []Step
+	}{
+		{"div.a > p", []Step{{Engine: EngineCSS, Value: "div.a > p"}}},
+		{"css=article .title", []Step{{Engine: EngineCSS, Value: "article .title"}}},
+		{"  #main  ", []Step{{Engine: EngineCSS, Value: "#main"}}},
+		{`a[href="x=y"]`, []Step{{Engine: EngineCSS, Value: `a[href="x=y"]`}}},
+		{`xpath=//button[@type="submit"]`, []Step{{Engine: EngineXPath, Value: `//button[@type="submit"]`}}},
+		{"//li[2]", []Step{{Engine: EngineXPath, Value: "//li[2]"}}},
+		{"(//li)[1]", []Step{{Engine: EngineXPath, Value: "(//li)[1]"}}},
+		{"text=Sign in", []Step{{Engine: EngineText, Value: "Sign in"}}},
+		{`text="Sign in"`, []Step{{Engine: EngineText, Value: "Sign in", Exact: true}}},
+		{"text='Sign in'", []Step{{Engine: EngineText, Value: "Sign in", Exact: true}}},
+		{`text="Sign in'`, []Step{{Engine: EngineText, Value: `"Sign in'`}}},
+		{"role=button", []Step{{Engine: EngineRole, Role: "button"}}},
+		{`role=button[name="Submit"]`, []Step{{Engine: EngineRole, Role: "button", Name: "Submit"}}},
+		{"role=*[name='Close']", []Step{{Engine: EngineRole, Role: "*", Name: "Close"}}},
+		{`role=[name="Close"]`, []Step{{Engine: EngineRole, Role: "*", Name: "Close"}}},
+		{"role=list >> role=listitem >> nth=2", []Step{
+			{Engine: EngineRole, Role: "list"},
+			{Engine: EngineRole, Role: "listitem"},
+			{Engine: EngineNth, Index: 2},
+		}},
+		{"ul >> nth=-1", []Step{{Engine: EngineCSS, Value: "ul"}, {Engine: EngineNth, Index: -1}}},
+		{"#menu >> text=Home", []Step{{Engine: EngineCSS, Value: "#menu"}, {Engine: EngineText, Value: "Home"}}},
+	}
+	for _, tt := range tests {
+		sel, err := Parse(tt.raw)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.raw, err)
+			continue
+		}
+		if !reflect.DeepEqual(sel.Steps, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.raw, sel.Steps, tt.want)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"   ",
+		"div[",
+		"xpath=//div[",
+		"css=",
+		"text=",
+		"nth=0",
+		"div >> nth=x",
+		"div >>  >> p",
+		`role=button[name="x"`,
+		"role=button[label='x']",
+		"role=button[name=Submit]",
+	} {
+		if sel, err := Parse(raw); err == nil {
+			t.Errorf("Parse(%q) = %+v, want error", raw, sel.Steps)
+		}
+	}
+}
+
+func TestSelector_CSS(t *testing.T) {
+	tests := []struct {
+		raw    string
+		css    string
+		native bool
+	}{
+		{"div.a", "div.a", true},
+		{"css=div.a", "div.a", true},
+		{"div >> p", "", false},
+		{"text=x", "", false},
+	}
+	for _, tt := range tests {
+		sel, err := Parse(tt.raw)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.raw, err)
+		}
+		if css, ok := sel.CSS(); css != tt.css || ok != tt.native {
+			t.Errorf("CSS() of %q = %q, %v, want %q, %v", tt.raw, css, ok, tt.css, tt.native)
+		}
+	}
+}
+
+const queryDoc = `<html><head><title>Sign in</title></head><body>
+<nav id="menu" data-id="nav">
+  <ul data-id="menu-list">
+    <li data-id="m1"><a href="/" data-id="home">Home</a></li>
+    <li data-id="m2"><a href="/about" data-id="about">About us</a></li>
+    <li data-id="m3"><a data-id="anchor">No link</a></li>
+  </ul>
+</nav>
+<main data-id="main">
+  <h1 data-id="h1">Welcome</h1>
+  <ul data-id="list">
+    <li data-id="i1">First</li>
+    <li data-id="i2">Second <b data-id="b2">item</b></li>
+    <li data-id="i3">Third</li>
+  </ul>
+  <form data-id="form">
+    <label for="email" data-id="label">E-mail</label>
+    <input id="email" type="email" data-id="email">
+    <label data-id="wrap">Remember me <input type="checkbox" data-id="remember"></label>
+    <input type="submit" value="Sign in" data-id="submit">
+    <button aria-label="Close dialog" data-id="close">x</button>
+    <div role="button" data-id="fake">Sign in</div>
+    <span id="lbl" data-id="lbl">Search  the   site</span>
+    <input type="search" aria-labelledby="lbl" data-id="search">
+  </form>
+  <p data-id="p-hidden" hidden>Sign in</p>
+  <img src="a.png" alt="" data-id="spacer">
+  <img src="b.png" alt="Logo" data-id="logo">
+</main>
+<script>var s = "Sign in";</script>
+</body></html>`
+
+func TestQueryAll(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(queryDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sel  string
+		want string // data-id values of the matches, in order
+	}{
+		// css
+		{"li", "m1 m2 m3 i1 i2 i3"},
+		{"main li", "i1 i2 i3"},
+		{"css=#menu a[href]", "home about"},
+		{"li, h1", "m1 m2 m3 h1 i1 i2 i3"},
+		{".missing", ""},
+
+		// xpath
+		{"//h1", "h1"},
+		{`xpath=//input[@type="submit"]`, "submit"},
+		{"//main//li[2]", "i2"},
+		{"//li/text()", ""}, // text nodes are dropped
+
+		// text
+		{"text=sign", "fake"},
+		{"text=second", "i2"},
+		{"text=item", "b2"},
+		{`text="Second item"`, "i2"},
+		{`text="second"`, ""},
+		{"text=us", "about"},
+
+		// role
+		{"role=link", "home about"},
+		{"role=listitem", "m1 m2 m3 i1 i2 i3"},
+		{"role=button", "submit close fake"},
+		{`role=button[name="sign in"]`, "submit fake"},
+		{`role=button[name="Close dialog"]`, "close"},
+		{`role=textbox[name="E-mail"]`, "email"},
+		{`role=checkbox[name="Remember me"]`, "remember"},
+		{`role=searchbox[name="Search the site"]`, "search"},
+		{"role=presentation", "spacer"},
+		{`role=img[name="Logo"]`, "logo"},
+		{`role=*[name="Welcome"]`, "h1"},
+		{"role=navigation", "nav"},
+
+		// chains and nth
+		{"main >> li", "i1 i2 i3"},
+		{"#menu >> role=link", "home about"},
+		{"role=list >> role=listitem >> nth=1", "m2"},
+		{"li >> nth=0", "m1"},
+		{"li >> nth=-1", "i3"},
+		{"li >> nth=6", ""},
+		{"main >> //li", "i1 i2 i3"}, // absolute XPath is relative to the scope
+		{"ul >> text=third", "i3"},
+		{"nav >> text=first", ""},
+		{"li >> b", "b2"},
+		{"ul >> li >> nth=0 >> text=home", "home"},
+	}
+	for _, tt := range tests {
+		sel, err := Parse(tt.sel)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.sel, err)
+			continue
+		}
+		var got []string
+		for _, n := range sel.QueryAll(doc) {
+			got = append(got, attr(n, "data-id"))
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("QueryAll(%q) = %q, want %q", tt.sel, strings.Join(got, " "), tt.want)
+		}
+	}
+}
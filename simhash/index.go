@@ -0,0 +1,259 @@
+package simhash
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// indexMagic starts a serialized Index.
+const indexMagic = "SHIX\x01"
+
+// Match is a fingerprint found by Index.Query.
+type Match struct {
+	ID          string
+	Fingerprint uint64
+	Distance    int
+}
+
+// Index finds stored fingerprints within Hamming distance K of a query
+// without comparing against every entry.
+//
+// It splits the 64 bits into K+1 bands and keeps one hash table per band.
+// Two fingerprints at distance ≤ K differ in at most K bands, so they agree
+// on at least one band (pigeonhole); a query only verifies the entries
+// sharing one of its band values. Safe for concurrent use.
+type Index struct {
+	k     int
+	bands []band
+
+	mu     sync.RWMutex
+	fps    map[string]uint64
+	tables []map[uint64][]string
+}
+
+// band is a bit range [shift, shift+width) of a fingerprint.
+type band struct {
+	shift, width uint
+}
+
+func (b band) key(fp uint64) uint64 {
+	return (fp >> b.shift) & (1<<b.width - 1)
+}
+
+// NewIndex returns an empty index for lookups within distance k (0-63).
+func NewIndex(k int) *Index {
+	k = min(max(k, 0), 63)
+	n := k + 1
+	x := &Index{
+		k:      k,
+		bands:  make([]band, n),
+		fps:    make(map[string]uint64),
+		tables: make([]map[uint64][]string, n),
+	}
+	shift := uint(0)
+	for i := range x.bands {
+		width := uint(64 / n)
+		if i < 64%n {
+			width++
+		}
+		x.bands[i] = band{shift: shift, width: width}
+		x.tables[i] = make(map[uint64][]string)
+		shift += width
+	}
+	return x
+}
+
+// K returns the distance the index answers queries for.
+func (x *Index) K() int { return x.k }
+
+// Len returns the number of stored fingerprints.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.fps)
+}
+
+// Add stores fp under id, replacing any fingerprint already stored for id.
+func (x *Index) Add(id string, fp uint64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if old, ok := x.fps[id]; ok {
+		if old == fp {
+			return
+		}
+		x.remove(id, old)
+	}
+	x.fps[id] = fp
+	for i, b := range x.bands {
+		k := b.key(fp)
+		x.tables[i][k] = append(x.tables[i][k], id)
+	}
+}
+
+// Remove deletes id from the index. It reports whether id was present.
+func (x *Index) Remove(id string) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	fp, ok := x.fps[id]
+	if ok {
+		x.remove(id, fp)
+	}
+	return ok
+}
+
+func (x *Index) remove(id string, fp uint64) {
+	delete(x.fps, id)
+	for i, b := range x.bands {
+		k := b.key(fp)
+		ids := x.tables[i][k]
+		for j, other := range ids {
+			if other == id {
+				ids[j] = ids[len(ids)-1]
+				ids = ids[:len(ids)-1]
+				break
+			}
+		}
+		if len(ids) == 0 {
+			delete(x.tables[i], k)
+		} else {
+			x.tables[i][k] = ids
+		}
+	}
+}
+
+// Get returns the fingerprint stored for id.
+func (x *Index) Get(id string) (uint64, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	fp, ok := x.fps[id]
+	return fp, ok
+}
+
+// Query returns every stored fingerprint within distance K of fp, closest
+// first (ties by ID).
+func (x *Index) Query(fp uint64) []Match {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	seen := make(map[string]bool)
+	var matches []Match
+	for i, b := range x.bands {
+		for _, id := range x.tables[i][b.key(fp)] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			stored := x.fps[id]
+			if d := Distance(fp, stored); d <= x.k {
+				matches = append(matches, Match{ID: id, Fingerprint: stored, Distance: d})
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// Nearest returns the closest stored fingerprint within distance K of fp.
+func (x *Index) Nearest(fp uint64) (Match, bool) {
+	matches := x.Query(fp)
+	if len(matches) == 0 {
+		return Match{}, false
+	}
+	return matches[0], true
+}
+
+// WriteTo serializes the index: a header with K and the entry count, then
+// each entry's fingerprint and ID. Band tables are rebuilt on read.
+func (x *Index) WriteTo(w io.Writer) (int64, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	var n int64
+	write := func(p []byte) error {
+		m, err := bw.Write(p)
+		n += int64(m)
+		return err
+	}
+	var buf [binary.MaxVarintLen64]byte
+	uvarint := func(v uint64) error {
+		return write(buf[:binary.PutUvarint(buf[:], v)])
+	}
+
+	if err := write([]byte(indexMagic)); err != nil {
+		return n, err
+	}
+	if err := uvarint(uint64(x.k)); err != nil {
+		return n, err
+	}
+	if err := uvarint(uint64(len(x.fps))); err != nil {
+		return n, err
+	}
+	var fpBuf [8]byte
+	for id, fp := range x.fps {
+		binary.LittleEndian.PutUint64(fpBuf[:], fp)
+		if err := write(fpBuf[:]); err != nil {
+			return n, err
+		}
+		if err := uvarint(uint64(len(id))); err != nil {
+			return n, err
+		}
+		if err := write([]byte(id)); err != nil {
+			return n, err
+		}
+	}
+	return n, bw.Flush()
+}
+
+// ReadIndex loads an index written by WriteTo.
+func ReadIndex(r io.Reader) (*Index, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(indexMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("simhash: reading index header: %w", err)
+	}
+	if string(magic) != indexMagic {
+		return nil, errors.New("simhash: not a serialized index")
+	}
+	k, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("simhash: reading index header: %w", err)
+	}
+	if k > 63 {
+		return nil, fmt.Errorf("simhash: invalid index distance %d", k)
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("simhash: reading index header: %w", err)
+	}
+
+	x := NewIndex(int(k))
+	var fpBuf [8]byte
+	for i := uint64(0); i < count; i++ {
+		if _, err := io.ReadFull(br, fpBuf[:]); err != nil {
+			return nil, fmt.Errorf("simhash: reading entry %d: %w", i, err)
+		}
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("simhash: reading entry %d: %w", i, err)
+		}
+		if size > 1<<20 {
+			return nil, fmt.Errorf("simhash: entry %d: id too long (%d bytes)", i, size)
+		}
+		id := make([]byte, size)
+		if _, err := io.ReadFull(br, id); err != nil {
+			return nil, fmt.Errorf("simhash: reading entry %d: %w", i, err)
+		}
+		x.Add(string(id), binary.LittleEndian.Uint64(fpBuf[:]))
+	}
+	return x, nil
+}
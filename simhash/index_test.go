@@ -0,0 +1,202 @@
+package simhash
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// flipBits returns fp with n distinct random bits flipped.
+func flipBits(rng *rand.Rand, fp uint64, n int) uint64 {
+	for _, bit := range rng.Perm(64)[:n] {
+		fp ^= 1 << uint(bit)
+	}
+	return fp
+}
+
+func TestIndex_MatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, k := range []int{0, 3, 6} {
+		t.Run(fmt.Sprintf("k=%d", k), func(t *testing.T) {
+			x := NewIndex(k)
+			stored := make(map[string]uint64)
+			var bases []uint64
+			for i := 0; i < 2000; i++ {
+				fp := rng.Uint64()
+				// Plant near neighbors of earlier entries.
+				if len(bases) > 0 && i%3 == 0 {
+					fp = flipBits(rng, bases[rng.Intn(len(bases))], rng.Intn(k+3))
+				}
+				bases = append(bases, fp)
+				id := fmt.Sprintf("doc-%d", i)
+				x.Add(id, fp)
+				stored[id] = fp
+			}
+			if x.Len() != len(stored) {
+				t.Fatalf("Len = %d, want %d", x.Len(), len(stored))
+			}
+
+			for q := 0; q < 300; q++ {
+				query := flipBits(rng, bases[rng.Intn(len(bases))], rng.Intn(k+3))
+				var want []string
+				for id, fp := range stored {
+					if Distance(query, fp) <= k {
+						want = append(want, id)
+					}
+				}
+				var got []string
+				for _, m := range x.Query(query) {
+					if m.Distance != Distance(query, m.Fingerprint) || m.Fingerprint != stored[m.ID] {
+						t.Fatalf("bad match %+v", m)
+					}
+					got = append(got, m.ID)
+				}
+				sort.Strings(want)
+				sort.Strings(got)
+				if fmt.Sprint(got) != fmt.Sprint(want) {
+					t.Fatalf("query %016x: got %v, want %v", query, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestIndex_QueryOrder(t *testing.T) {
+	x := NewIndex(3)
+	x.Add("far", 0b111)
+	x.Add("exact", 0)
+	x.Add("b-near", 0b1)
+	x.Add("a-near", 0b10)
+
+	var ids []string
+	for _, m := range x.Query(0) {
+		ids = append(ids, m.ID)
+	}
+	want := []string{"exact", "a-near", "b-near", "far"}
+	if fmt.Sprint(ids) != fmt.Sprint(want) {
+		t.Errorf("Query order = %v, want %v", ids, want)
+	}
+
+	m, ok := x.Nearest(0b11)
+	if !ok || m.Distance != 1 {
+		t.Errorf("Nearest = %+v, %v", m, ok)
+	}
+	if _, ok := x.Nearest(^uint64(0)); ok {
+		t.Error("Nearest found a match beyond K")
+	}
+}
+
+func TestIndex_ReplaceAndRemove(t *testing.T) {
+	x := NewIndex(2)
+	x.Add("page", 0)
+	x.Add("page", ^uint64(0))
+	if x.Len() != 1 {
+		t.Fatalf("Len after replace = %d, want 1", x.Len())
+	}
+	if len(x.Query(0)) != 0 {
+		t.Error("old fingerprint still found after replace")
+	}
+	if fp, ok := x.Get("page"); !ok || fp != ^uint64(0) {
+		t.Errorf("Get = %x, %v", fp, ok)
+	}
+
+	if !x.Remove("page") {
+		t.Error("Remove reported a missing id")
+	}
+	if x.Remove("page") {
+		t.Error("second Remove reported a present id")
+	}
+	if x.Len() != 0 || len(x.Query(^uint64(0))) != 0 {
+		t.Error("removed fingerprint still indexed")
+	}
+	for i, table := range x.tables {
+		if len(table) != 0 {
+			t.Errorf("band %d table not empty: %v", i, table)
+		}
+	}
+}
+
+func TestIndex_ConcurrentUse(t *testing.T) {
+	x := NewIndex(3)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 500; i++ {
+				id := fmt.Sprintf("w%d-%d", w, i)
+				fp := rng.Uint64()
+				x.Add(id, fp)
+				if len(x.Query(fp)) == 0 {
+					t.Errorf("%s not found right after Add", id)
+					return
+				}
+				if i%5 == 0 {
+					x.Remove(id)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if want := 8 * 400; x.Len() != want {
+		t.Errorf("Len = %d, want %d", x.Len(), want)
+	}
+}
+
+func TestIndex_Serialization(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	x := NewIndex(4)
+	for i := 0; i < 500; i++ {
+		x.Add(fmt.Sprintf("https://example.com/%d", i), rng.Uint64())
+	}
+	x.Add("ünïcode id", 42)
+
+	var buf bytes.Buffer
+	n, err := x.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo reported %d bytes, wrote %d", n, buf.Len())
+	}
+
+	y, err := ReadIndex(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y.K() != x.K() || y.Len() != x.Len() {
+		t.Fatalf("read K=%d Len=%d, want K=%d Len=%d", y.K(), y.Len(), x.K(), x.Len())
+	}
+	for id, fp := range x.fps {
+		if got, ok := y.Get(id); !ok || got != fp {
+			t.Fatalf("entry %q: got %x, %v; want %x", id, got, ok, fp)
+		}
+		if m, ok := y.Nearest(flipBits(rng, fp, 2)); !ok || m.Distance > 2 {
+			t.Fatalf("entry %q not found near its fingerprint: %+v", id, m)
+		}
+	}
+}
+
+func TestReadIndex_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	x := NewIndex(3)
+	x.Add("a", 1)
+	if _, err := x.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	for name, input := range map[string][]byte{
+		"empty":     nil,
+		"bad magic": []byte("NOPE\x01\x03\x00"),
+		"truncated": data[:len(data)-1],
+	} {
+		if _, err := ReadIndex(bytes.NewReader(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
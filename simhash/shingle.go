@@ -0,0 +1,210 @@
+package simhash
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Weighting selects how much each shingle contributes to a fingerprint.
+type Weighting int
+
+const (
+	// WeightTF counts every occurrence of a shingle (term frequency), as
+	// Fingerprint does.
+	WeightTF Weighting = iota
+
+	// WeightBinary counts each distinct shingle once.
+	WeightBinary
+
+	// WeightIDF scales term frequency by the shingle's inverse document
+	// frequency in Options.IDF, so words common to the whole corpus
+	// (navigation, site name) matter less.
+	WeightIDF
+)
+
+// Options configures tokenization and weighting for FingerprintWith.
+//
+// Text is split into runs by script. Runs in scripts written without spaces
+// (Han, Hiragana, Katakana, Thai, Lao, Khmer, Myanmar) become overlapping
+// character n-grams; other runs become lowercased words with punctuation
+// stripped, shingled into word n-grams. A run shorter than its n-gram size
+// becomes a single shingle.
+type Options struct {
+	// CharNGram is the character shingle size for unsegmented scripts.
+	// Default: 2.
+	CharNGram int
+
+	// WordNGram is the word shingle size for space-delimited scripts.
+	// Default: 1.
+	WordNGram int
+
+	// Weighting defaults to WeightTF.
+	Weighting Weighting
+
+	// IDF supplies the document frequencies for WeightIDF.
+	IDF *IDF
+}
+
+// FingerprintWith computes a 64-bit SimHash of text with script-aware
+// shingling and optional weighting. Returns 0 for text without shingles.
+func FingerprintWith(text string, opts Options) uint64 {
+	shingles := Shingles(text, opts)
+	if len(shingles) == 0 {
+		return 0
+	}
+
+	// Accumulate in first-occurrence order so float sums are deterministic.
+	weights := make(map[string]float64, len(shingles))
+	var order []string
+	for _, s := range shingles {
+		if _, ok := weights[s]; !ok {
+			order = append(order, s)
+		}
+		if opts.Weighting == WeightBinary {
+			weights[s] = 1
+		} else {
+			weights[s]++
+		}
+	}
+
+	var vector [64]float64
+	for _, s := range order {
+		w := weights[s]
+		if opts.Weighting == WeightIDF && opts.IDF != nil {
+			w *= opts.IDF.Weight(s)
+		}
+		h := fnv.New64a()
+		h.Write([]byte(s))
+		hash := h.Sum64()
+
+		for i := 0; i < 64; i++ {
+			if hash&(1<<uint(i)) != 0 {
+				vector[i] += w
+			} else {
+				vector[i] -= w
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for i := 0; i < 64; i++ {
+		if vector[i] > 0 {
+			fingerprint |= 1 << uint(i)
+		}
+	}
+	return fingerprint
+}
+
+// Shingles splits text into the shingles FingerprintWith hashes.
+func Shingles(text string, opts Options) []string {
+	charN := opts.CharNGram
+	if charN <= 0 {
+		charN = 2
+	}
+	wordN := opts.WordNGram
+	if wordN <= 0 {
+		wordN = 1
+	}
+
+	var shingles []string
+	var chars []rune
+	var words []string
+	var word strings.Builder
+
+	flushWord := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	flushWords := func() {
+		flushWord()
+		shingles = append(shingles, nGrams(words, wordN)...)
+		words = words[:0]
+	}
+	flushChars := func() {
+		if len(chars) == 0 {
+			return
+		}
+		if len(chars) < charN {
+			shingles = append(shingles, string(chars))
+		}
+		for i := 0; i+charN <= len(chars); i++ {
+			shingles = append(shingles, string(chars[i:i+charN]))
+		}
+		chars = chars[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isUnsegmented(r):
+			flushWords()
+			chars = append(chars, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			flushChars()
+			word.WriteRune(unicode.ToLower(r))
+		default:
+			// Spaces and punctuation end a word; word n-grams run across
+			// them.
+			flushChars()
+			flushWord()
+		}
+	}
+	flushWords()
+	flushChars()
+	return shingles
+}
+
+// nGrams joins consecutive tokens into n-grams; fewer than n tokens form a
+// single shingle.
+func nGrams(tokens []string, n int) []string {
+	switch {
+	case len(tokens) == 0:
+		return nil
+	case n == 1:
+		return append([]string(nil), tokens...)
+	case len(tokens) < n:
+		return []string{strings.Join(tokens, "_")}
+	}
+	return makeShingles(tokens, n)
+}
+
+// isUnsegmented reports whether r belongs to a script written without
+// spaces between words.
+func isUnsegmented(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana,
+		unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar)
+}
+
+// IDF holds document frequencies of shingles over a corpus, for
+// WeightIDF. Build it with Add before fingerprinting; it is not safe for
+// concurrent Add calls.
+type IDF struct {
+	docs int
+	df   map[string]int
+}
+
+// NewIDF returns an empty IDF table.
+func NewIDF() *IDF {
+	return &IDF{df: make(map[string]int)}
+}
+
+// Add counts the distinct shingles of one document, tokenized with opts.
+func (x *IDF) Add(text string, opts Options) {
+	x.docs++
+	seen := make(map[string]bool)
+	for _, s := range Shingles(text, opts) {
+		if !seen[s] {
+			seen[s] = true
+			x.df[s]++
+		}
+	}
+}
+
+// Weight returns the smoothed inverse document frequency of a shingle:
+// ln((1+N)/(1+df)) + 1. Unseen shingles get the highest weight.
+func (x *IDF) Weight(shingle string) float64 {
+	return math.Log(float64(1+x.docs)/float64(1+x.df[shingle])) + 1
+}
@@ -0,0 +1,137 @@
+package simhash
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShingles_CJKCharacterBigrams(t *testing.T) {
+	got := Shingles("东京大学", Options{})
+	want := []string{"东京", "京大", "大学"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Shingles = %q, want %q", got, want)
+	}
+}
+
+func TestShingles_MixedScripts(t *testing.T) {
+	got := Shingles("Go语言 is fun, 日本語テキスト!", Options{})
+	want := []string{"go", "语言", "is", "fun", "日本", "本語", "語テ", "テキ", "キス", "スト"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Shingles = %q, want %q", got, want)
+	}
+}
+
+func TestShingles_WordNGrams(t *testing.T) {
+	got := Shingles("The quick, brown fox", Options{WordNGram: 2})
+	want := []string{"the_quick", "quick_brown", "brown_fox"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Shingles = %q, want %q", got, want)
+	}
+
+	// A run shorter than the n-gram size is kept whole.
+	got = Shingles("hello", Options{WordNGram: 3})
+	if !reflect.DeepEqual(got, []string{"hello"}) {
+		t.Errorf("short run: Shingles = %q", got)
+	}
+	got = Shingles("字", Options{CharNGram: 3})
+	if !reflect.DeepEqual(got, []string{"字"}) {
+		t.Errorf("short CJK run: Shingles = %q", got)
+	}
+}
+
+func TestShingles_HangulIsSpaceDelimited(t *testing.T) {
+	got := Shingles("안녕하세요 세계", Options{})
+	want := []string{"안녕하세요", "세계"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Shingles = %q, want %q", got, want)
+	}
+}
+
+func TestFingerprintWith_CJKNearDuplicates(t *testing.T) {
+	a := "今天天气很好，我们一起去公园散步吧。公园里有很多花，还有一个很大的湖。"
+	b := "今天天气很好，我们一起去公园散步吧。公园里有很多花，还有一个很小的湖。"
+	c := "量子计算机利用量子比特进行运算，其原理与传统计算机完全不同。"
+
+	near := Distance(FingerprintWith(a, Options{}), FingerprintWith(b, Options{}))
+
+	// Fields sees each sentence as a single word, so one changed character
+	// yields an unrelated fingerprint.
+	if fields := Distance(Fingerprint(a), Fingerprint(b)); fields <= near {
+		t.Errorf("Fingerprint distance %d not larger than FingerprintWith distance %d", fields, near)
+	}
+	far := Distance(FingerprintWith(a, Options{}), FingerprintWith(c, Options{}))
+	if near > 10 {
+		t.Errorf("near-duplicate CJK texts have distance %d", near)
+	}
+	if far <= near {
+		t.Errorf("unrelated CJK text distance %d not larger than near-duplicate distance %d", far, near)
+	}
+}
+
+func TestFingerprintWith_MixedScriptCorpus(t *testing.T) {
+	corpus := []string{
+		"Purify 是一个用 Go 编写的网页抓取服务，可以把网页转换为干净的 Markdown。",
+		"Purify は Go で書かれたウェブスクレイピングサービスで、ページをきれいな Markdown に変換します。",
+		"Purify is a web scraping service written in Go that turns pages into clean Markdown.",
+		"Purify 是一个用 Go 编写的网页抓取服务，可以把网页转换成干净的 Markdown。",
+	}
+	fps := make([]uint64, len(corpus))
+	for i, doc := range corpus {
+		fps[i] = FingerprintWith(doc, Options{})
+		if fps[i] == 0 {
+			t.Fatalf("doc %d: zero fingerprint", i)
+		}
+	}
+
+	dup := Distance(fps[0], fps[3])
+	for _, other := range []int{1, 2} {
+		if d := Distance(fps[0], fps[other]); d <= dup {
+			t.Errorf("doc 0 vs doc %d distance %d not larger than its near-duplicate's %d", other, d, dup)
+		}
+	}
+}
+
+func TestFingerprintWith_Weighting(t *testing.T) {
+	text := "spam spam spam spam spam spam eggs ham"
+	tf := FingerprintWith(text, Options{})
+	binary := FingerprintWith(text, Options{Weighting: WeightBinary})
+	if tf == binary {
+		t.Error("TF and binary weighting should differ when one term dominates")
+	}
+
+	// IDF weights common shingles below rare ones; with an empty corpus
+	// every weight is 1 and the result matches TF.
+	idf := NewIDF()
+	if fp := FingerprintWith(text, Options{Weighting: WeightIDF, IDF: idf}); fp != tf {
+		t.Errorf("IDF with an empty corpus = %x, want TF fingerprint %x", fp, tf)
+	}
+	for _, doc := range []string{
+		"site menu login article about cats",
+		"site menu login article about dogs",
+		"site menu login article about birds",
+	} {
+		idf.Add(doc, Options{})
+	}
+	if idf.Weight("site") >= idf.Weight("cats") {
+		t.Errorf("common term weight %.2f not below rare term weight %.2f", idf.Weight("site"), idf.Weight("cats"))
+	}
+}
+
+func TestFingerprintWith_Empty(t *testing.T) {
+	if fp := FingerprintWith(" ,.!? ", Options{}); fp != 0 {
+		t.Errorf("punctuation-only input should produce fingerprint 0, got: %064b", fp)
+	}
+}
+
+func TestFingerprintWith_Deterministic(t *testing.T) {
+	idf := NewIDF()
+	idf.Add("alpha beta gamma", Options{})
+	opts := Options{Weighting: WeightIDF, IDF: idf}
+	text := "alpha beta gamma delta epsilon 中文文本 alpha"
+	first := FingerprintWith(text, opts)
+	for i := 0; i < 20; i++ {
+		if fp := FingerprintWith(text, opts); fp != first {
+			t.Fatalf("run %d: fingerprint %x differs from %x", i, fp, first)
+		}
+	}
+}
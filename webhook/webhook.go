@@ -15,7 +15,7 @@ import (
 
 // Event is the payload sent to webhook endpoints.
 type Event struct {
-	Type      string      `json:"type"`      // e.g. "batch.completed", "crawl.page", "crawl.completed", "crawl.failed"
+	Type      string      `json:"type"`      // e.g. "batch.completed", "crawl.page", "crawl.completed", "crawl.failed", "monitor.changed"
 	JobID     string      `json:"job_id"`
 	Timestamp int64       `json:"timestamp"`
 	Data      interface{} `json:"data"`